@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+)
+
+var diffCommand = command{
+	name:    "diff",
+	summary: "compare the coverage of two coverage files",
+	usage:   "[flags] <base-coverage> <head-coverage>",
+	description: "Compare the coverage of two coverage files, e.g. of the main branch and of a pull request. The totals of the provider and of every changed resource\n" +
+		"are printed, together with the properties newly covered (+), no longer covered (-), and the uncovered ones added into (>) or removed from (<) the counts.",
+	examples: []string{
+		"diff -schema ./schema.json ./main/coverage.json ./coverage.json",
+		"diff -schema ./schema.json -fail-on-decrease ./main/coverage.json ./coverage.json",
+		"diff -base-schema ./old/schema.json -schema ./schema.json ./coverage.json ./coverage.json",
+	},
+	setup: setupDiff,
+}
+
+func setupDiff(fs *flag.FlagSet) func(args []string) {
+	schemaFile := fs.String("schema", "", "the schema dump of azurerm provider")
+	baseSchemaFile := fs.String("base-schema", "", "the schema dump to run the base coverage with, defaults to -schema")
+	ignoreSchemas := fs.String("ignore-schema", "", "the schema to ignore of azurerm provider")
+	ignoreUncoveredResources := fs.Bool("ignore-uncovered-resources", false, "ignore uncovered resources")
+	format := fs.String("format", "text", "the output format, possible values: text, json")
+	compact := fs.Bool("compact", false, "only print one line per changed resource in the text format")
+	color := fs.String("color", report.ColorAuto, "whether to use colors in the text format, possible values: auto, always, never")
+	failOnDecrease := fs.Bool("fail-on-decrease", false, "exit with 1 if the total percent decreases")
+
+	return func(args []string) {
+		if len(args) != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if *format != "text" && *format != "json" {
+			exitOnError(fmt.Errorf("unknown format %q", *format))
+		}
+		if *baseSchemaFile == "" {
+			*baseSchemaFile = *schemaFile
+		}
+
+		results := make([]*coverage.Result, 0, 2)
+		for i, schemaFile := range []string{*baseSchemaFile, *schemaFile} {
+			coverageMap, err := jsonhelper.ParseCoverageFile(args[i])
+			if err != nil {
+				exitOnError(err)
+			}
+			schema, err := jsonhelper.ParseSchema(schemaFile)
+			if err != nil {
+				exitOnError(err)
+			}
+			result, err := runReport(schema.ProviderSchema.ResourcesMap, coverageMap, splitList(*ignoreSchemas), *ignoreUncoveredResources)
+			if err != nil {
+				exitOnError(err)
+			}
+			results = append(results, result)
+		}
+
+		delta := coverage.Diff(results[0], results[1])
+		var err error
+		if *format == "json" {
+			err = report.RenderDiffJSON(os.Stdout, delta)
+		} else {
+			err = report.RenderDiff(os.Stdout, delta, report.DiffOptions{
+				Compact: *compact,
+				Color:   *color,
+			})
+		}
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if *failOnDecrease && delta.Head.Percent() < delta.Base.Percent() {
+			exitOnError(fmt.Errorf("the total percent decreases from %.2f%% to %.2f%%", delta.Base.Percent(), delta.Head.Percent()))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
+)
+
+var explainCommand = command{
+	name:    "explain",
+	summary: "explain how a property is matched with the coverage entries",
+	usage:   "[flags] <resource> <pointer>",
+	description: "Explain how a property is matched with the coverage entries: the schema path, the indexes found for the blocks along the path,\n" +
+		"the display pointer, the coverage keys matched and the ignore rule applied. The pointer could be a display pointer,\n" +
+		"a pointer with any index, or a schema path.",
+	examples: []string{
+		"explain -input ./coverage.json -schema ./schema.json azurerm_linux_web_app /site_config/0/always_on",
+		"explain -input ./coverage.json -schema ./schema.json azurerm_linux_web_app site_config/ip_restriction/priority",
+	},
+	setup: setupExplain,
+}
+
+func setupExplain(fs *flag.FlagSet) func(args []string) {
+	inputs := addInputFlags(fs)
+
+	return func(args []string) {
+		if len(args) != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+
+		cfg, err := inputs.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		coverageMap, resources, err := loadInputs(cfg)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if err := runExplain(os.Stdout, resources, coverageMap, cfg.IgnoreSchemas, args[0], args[1]); err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+// runExplain writes how the property of ptrStr of the resource is matched.
+func runExplain(w io.Writer, resources map[string]jsonhelper.ResourceJSON, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage, ignoreSchemas []string, resType, ptrStr string) error {
+	r, err := runner.NwRunner(runner.Opts{
+		Resources:     resources,
+		CoverageMap:   coverageMap,
+		IgnoreSchemas: ignoreSchemas,
+	})
+	if err != nil {
+		return err
+	}
+
+	explanation, err := r.Explain(resType, ptrStr)
+	if err != nil {
+		return err
+	}
+	return report.RenderExplain(w, *explanation)
+}
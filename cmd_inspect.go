@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsontree"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
+)
+
+var inspectCommand = command{
+	name:        "inspect",
+	summary:     "print the nested schema of a resource with coverage marks",
+	usage:       "[flags] <resource>",
+	description: "Print the nested schema of a resource as a tree, each property is marked as covered, uncovered or ignored.",
+	examples: []string{
+		"inspect -input ./coverage.json -schema ./schema.json azurerm_linux_web_app",
+		"inspect -input ./coverage.json -schema ./schema.json -uncovered azurerm_linux_web_app",
+		"inspect -input ./coverage.json -schema ./schema.json -coverage-tree azurerm_linux_web_app",
+	},
+	setup: setupInspect,
+}
+
+func setupInspect(fs *flag.FlagSet) func(args []string) {
+	inputs := addInputFlags(fs)
+	uncoveredOnly := fs.Bool("uncovered", false, "only show uncovered properties")
+	coverageTree := fs.Bool("coverage-tree", false, "print the typed coverage tree of the resource instead, including the entries not found in the schema")
+	color := fs.String("color", report.ColorAuto, "whether to use colors, possible values: auto, always, never")
+
+	return func(args []string) {
+		if len(args) != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		resType := args[0]
+
+		cfg, err := inputs.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		coverageMap, resources, err := loadInputs(cfg)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if _, ok := resources[resType]; !ok {
+			exitOnError(fmt.Errorf("resource %q not found in schema", resType))
+		}
+
+		if *coverageTree {
+			r, err := runner.NwRunner(runner.Opts{
+				Resources:   resources,
+				CoverageMap: coverageMap,
+				Filter:      []string{resType},
+			})
+			if err != nil {
+				exitOnError(err)
+			}
+			root, ok := r.CoverageTree(resType)
+			if !ok {
+				exitOnError(fmt.Errorf("resource %q has no coverage entry", resType))
+			}
+			if err := jsontree.Fprint(os.Stdout, root); err != nil {
+				exitOnError(err)
+			}
+			return
+		}
+
+		if err := runInspect(os.Stdout, resources, resType, coverageMap, cfg.IgnoreSchemas, report.InspectOptions{
+			UncoveredOnly: *uncoveredOnly,
+			Color:         *color,
+		}); err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+// runInspect writes the nested schema of the resource with the coverage marks.
+func runInspect(w io.Writer, resources map[string]jsonhelper.ResourceJSON, resType string, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage, ignoreSchemas []string, opts report.InspectOptions) error {
+	r, err := runner.NwRunner(runner.Opts{
+		Resources:     resources,
+		CoverageMap:   coverageMap,
+		IgnoreSchemas: ignoreSchemas,
+		Filter:        []string{resType},
+	})
+	if err != nil {
+		return err
+	}
+	result, err := r.Run()
+	if err != nil {
+		return err
+	}
+	return report.RenderInspect(w, resType, resources[resType], result, opts)
+}
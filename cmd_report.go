@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/config"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+)
+
+var reportCommand = command{
+	name:        "report",
+	summary:     "report the coverage of the schema, it's the default command",
+	usage:       "[flags]",
+	description: "Report the coverage of the schema in one or more formats, optionally with diagnostics and badges.",
+	examples: []string{
+		"report -input ./coverage.json -schema ./schema.json -ignore-schema name,resource_group_name",
+		"report -input ./coverage.json -schema ./schema.json -format table -sort uncovered -top 20",
+		"report -input ./coverage.json -schema ./schema.json -format json,markdown -output coverage.json,coverage.md",
+		"report -config ./coverage.yaml -fail-under 60",
+		"report -input ./coverage.json -schema ./schema.json -format service,namespace-json -output -,namespaces.json -service-overrides ./services.yaml",
+		"report -input ./coverage.json -schema ./schema.json -format table -output coverage.txt -watch",
+		"-input ./coverage.json -schema ./schema.json -portal-output -diagnostics-output",
+	},
+	setup: setupReport,
+}
+
+var configCommand = command{
+	name:    "config",
+	summary: "print the effective configuration of the report command",
+	usage:   "[flags]",
+	description: "Print the configuration the report command runs with, which is the defaults overridden by the configuration file\n" +
+		"and then by the flags set explicitly. It accepts all the flags of the report command.",
+	examples: []string{
+		"config -config ./coverage.yaml",
+		"config -config ./coverage.yaml -format table -as json",
+	},
+	setup: setupConfig,
+}
+
+type reportFlags struct {
+	inputFlags
+	ignoreUncoveredResources *bool
+	diagnosticsOutput        *bool
+	diagnosticsFile          *string
+	portalOutput             *bool
+	failUnder                *float64
+	serviceOverrides         *string
+	badgeDir                 *string
+	badgeScope               *string
+	badgeThresholds          *string
+	format                   *string
+	output                   *string
+	tableSort                *string
+	tableTop                 *int
+	color                    *string
+	watch                    *bool
+	watchInterval            *time.Duration
+}
+
+func addReportFlags(fs *flag.FlagSet) reportFlags {
+	def := defaultConfig()
+	return reportFlags{
+		inputFlags:               addInputFlags(fs),
+		ignoreUncoveredResources: fs.Bool("ignore-uncovered-resources", def.IgnoreUncoveredResources, "ignore uncovered resources"),
+		diagnosticsOutput:        fs.Bool("diagnostics-output", def.Diagnostics.Enabled, "output diagnostics information"),
+		diagnosticsFile:          fs.String("diagnostics-file", def.Diagnostics.File, "the file to write plain-text diagnostics into, defaults to stderr"),
+		portalOutput:             fs.Bool("portal-output", false, "output to fit portal format, the same as -format portal"),
+		failUnder:                fs.Float64("fail-under", def.FailUnder, "exit with 1 if the total percent is lower than it, it's not checked if it's 0"),
+		serviceOverrides:         fs.String("service-overrides", def.ServiceOverrides, "the JSON or YAML file of the services and namespaces of resources, which override the ones derived from the mappings"),
+		badgeDir:                 fs.String("badge-dir", def.Badge.Dir, "the directory to write coverage badges into, badges are not written if empty"),
+		badgeScope:               fs.String("badge-scope", def.Badge.Scope, "the scope of badges, possible values: total, resource, service, namespace"),
+		badgeThresholds:          fs.String("badge-thresholds", def.Badge.Thresholds, "the colors of badges in the format of percent=color,..."),
+		format:                   fs.String("format", def.Outputs[0].Format, "the output formats separated by comma, possible values: "+strings.Join(report.Formats(), ", ")),
+		output:                   fs.String("output", "", "the output files separated by comma, one for each format, empty or - means stdout"),
+		tableSort:                fs.String("sort", def.Table.Sort, "the sort of the table format, possible values: percent, uncovered, name"),
+		tableTop:                 fs.Int("top", def.Table.Top, "the count of resources to show in the table format, all resources are shown if it's 0"),
+		color:                    fs.String("color", def.Table.Color, "whether to use colors in the table format, possible values: auto, always, never"),
+		watch:                    fs.Bool("watch", false, "keep running and rerun the changed resources every time the input file is changed, the outputs to stdout are only written once"),
+		watchInterval:            fs.Duration("watch-interval", time.Second, "the interval to poll the input file in watch mode"),
+	}
+}
+
+// config returns the defaults overridden by the configuration file and then by the flags set explicitly.
+func (f reportFlags) config() (config.Config, error) {
+	cfg, err := f.inputFlags.config()
+	if err != nil {
+		return cfg, err
+	}
+
+	visited := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) {
+		visited[fl.Name] = true
+		switch fl.Name {
+		case "ignore-uncovered-resources":
+			cfg.IgnoreUncoveredResources = *f.ignoreUncoveredResources
+		case "diagnostics-output":
+			cfg.Diagnostics.Enabled = *f.diagnosticsOutput
+		case "diagnostics-file":
+			cfg.Diagnostics.File = *f.diagnosticsFile
+		case "fail-under":
+			cfg.FailUnder = *f.failUnder
+		case "service-overrides":
+			cfg.ServiceOverrides = *f.serviceOverrides
+		case "badge-dir":
+			cfg.Badge.Dir = *f.badgeDir
+		case "badge-scope":
+			cfg.Badge.Scope = *f.badgeScope
+		case "badge-thresholds":
+			cfg.Badge.Thresholds = *f.badgeThresholds
+		case "sort":
+			cfg.Table.Sort = *f.tableSort
+		case "top":
+			cfg.Table.Top = *f.tableTop
+		case "color":
+			cfg.Table.Color = *f.color
+		}
+	})
+
+	if visited["format"] || visited["output"] {
+		formats := make([]string, 0, len(cfg.Outputs))
+		for _, o := range cfg.Outputs {
+			formats = append(formats, o.Format)
+		}
+		if visited["format"] {
+			formats = splitList(*f.format)
+		}
+		paths := make([]string, len(formats))
+		if visited["output"] {
+			paths = splitList(*f.output)
+			if len(paths) != len(formats) {
+				return cfg, fmt.Errorf("%d outputs are specified for %d formats", len(paths), len(formats))
+			}
+		}
+		cfg.Outputs = make([]config.Output, 0, len(formats))
+		for i, format := range formats {
+			cfg.Outputs = append(cfg.Outputs, config.Output{Format: format, Path: paths[i]})
+		}
+	}
+
+	// -portal-output is kept for compatibility, it turns the json output into the portal output.
+	if *f.portalOutput {
+		found := false
+		for i := range cfg.Outputs {
+			if cfg.Outputs[i].Format == "json" {
+				cfg.Outputs[i].Format = "portal"
+				found = true
+			}
+		}
+		if !found {
+			return cfg, errors.New("-portal-output turns the json output into the portal output, but there is no json output, use -format portal instead")
+		}
+	}
+	return cfg, nil
+}
+
+func setupReport(fs *flag.FlagSet) func(args []string) {
+	flags := addReportFlags(fs)
+
+	return func(args []string) {
+		cfg, err := flags.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		if *flags.watch {
+			err = runWatch(cfg, *flags.watchInterval)
+		} else {
+			err = runReportConfig(cfg)
+		}
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+func setupConfig(fs *flag.FlagSet) func(args []string) {
+	flags := addReportFlags(fs)
+	as := fs.String("as", "yaml", "the format to print the configuration in, possible values: yaml, json")
+
+	return func(args []string) {
+		cfg, err := flags.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		if err := cfg.Write(os.Stdout, *as); err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+// runReportConfig runs the report command with the effective configuration.
+func runReportConfig(cfg config.Config) error {
+	rep, err := newReportWriter(cfg)
+	if err != nil {
+		return err
+	}
+
+	coverageMap, resources, err := loadInputs(cfg)
+	if err != nil {
+		return err
+	}
+
+	result, err := runReport(resources, coverageMap, cfg.IgnoreSchemas, cfg.IgnoreUncoveredResources)
+	if err != nil {
+		return err
+	}
+	if err := rep.write(result, true); err != nil {
+		return err
+	}
+	return rep.checkFailUnder(result)
+}
+
+// reportWriter writes the badges, diagnostics and outputs of a result with the renderers created from the configuration.
+type reportWriter struct {
+	cfg       config.Config
+	renderers []report.Renderer
+	portal    bool
+	origins   map[string]coverage.Origin
+}
+
+func newReportWriter(cfg config.Config) (*reportWriter, error) {
+	if len(cfg.Outputs) == 0 {
+		return nil, fmt.Errorf("no output is configured")
+	}
+
+	rep := &reportWriter{
+		cfg:       cfg,
+		renderers: make([]report.Renderer, 0, len(cfg.Outputs)),
+	}
+	if cfg.ServiceOverrides != "" {
+		origins, err := config.LoadServiceOverrides(cfg.ServiceOverrides)
+		if err != nil {
+			return nil, err
+		}
+		rep.origins = origins
+	}
+	stdoutFormat := ""
+	for _, o := range cfg.Outputs {
+		renderer, err := report.NewRenderer(o.Format, report.Options{
+			Diagnostics: cfg.Diagnostics.Enabled,
+			Origins:     rep.origins,
+			Table: report.TableOptions{
+				Sort:  cfg.Table.Sort,
+				Top:   cfg.Table.Top,
+				Color: cfg.Table.Color,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		rep.renderers = append(rep.renderers, renderer)
+		rep.portal = rep.portal || o.Format == "portal"
+
+		if isStdout(o.Path) {
+			if stdoutFormat != "" {
+				return nil, fmt.Errorf("both %q and %q are written to stdout, please specify `-output` for each format", stdoutFormat, o.Format)
+			}
+			stdoutFormat = o.Format
+		}
+	}
+	return rep, nil
+}
+
+// write writes the badges, diagnostics and outputs of result, the outputs and diagnostics to stdout and stderr are skipped unless stdio is set.
+func (rep *reportWriter) write(result *coverage.Result, stdio bool) error {
+	cfg := rep.cfg
+	if cfg.Badge.Dir != "" {
+		thresholds, err := report.ParseBadgeThresholds(cfg.Badge.Thresholds)
+		if err != nil {
+			return err
+		}
+		if err := report.WriteBadges(cfg.Badge.Dir, result, report.BadgeOptions{
+			Scope:      cfg.Badge.Scope,
+			Thresholds: thresholds,
+			Origins:    rep.origins,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// the diagnostics are already in the portal output, unless a diagnostics file is specified.
+	if cfg.Diagnostics.Enabled && (!rep.portal || cfg.Diagnostics.File != "") {
+		if cfg.Diagnostics.File == "" {
+			if stdio {
+				diagOutput(os.Stderr, result)
+			}
+		} else if err := writeOutput(cfg.Diagnostics.File, func(w io.Writer) error {
+			diagOutput(w, result)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	for i, renderer := range rep.renderers {
+		if !stdio && isStdout(cfg.Outputs[i].Path) {
+			continue
+		}
+		if err := writeOutput(cfg.Outputs[i].Path, func(w io.Writer) error {
+			return renderer.Render(w, result)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rep *reportWriter) checkFailUnder(result *coverage.Result) error {
+	if rep.cfg.FailUnder > 0 && result.Percent() < rep.cfg.FailUnder {
+		return fmt.Errorf("the total percent %.2f%% is lower than %.2f%%", result.Percent(), rep.cfg.FailUnder)
+	}
+	return nil
+}
+
+func diagOutput(w io.Writer, result *coverage.Result) {
+	fmt.Fprintln(w, "----------------------------------------")
+	fmt.Fprintln(w, "resource coverage detail:")
+	for _, res := range result.SortedResources() {
+		fmt.Fprintf(w, "resource: %s, schema cnt: %d, coverage cnt: %d, percent: %.2f%%\n", res.Name, res.TotalCnt, res.CoveredCnt, res.Percent())
+	}
+	fmt.Fprintln(w, "----------------------------------------")
+
+	if issues := result.Issues(); len(issues) > 0 {
+		fmt.Fprintln(w, "coverage issue resources:")
+		for _, issue := range issues {
+			fmt.Fprintf(w, "%s: statics count: %d, coverage count: %d\n", issue.Name, issue.CoveredCnt, issue.EntryCnt)
+		}
+	}
+	fmt.Fprintln(w, "----------------------------------------")
+	fmt.Fprintf(w, "total resources: %d\n", len(result.Resources))
+	fmt.Fprintf(w, "total count schema: %d, coverage: %d, percent: %.2f%%\n", result.TotalCnt, result.CoveredCnt, result.Percent())
+	fmt.Fprintln(w, "----------------------------------------")
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/config"
+)
+
+func TestReportFlagsConfig(t *testing.T) {
+	const yamlConfig = `input: ./coverage.json
+schema: ./schema.json
+ignore_schemas: [name]
+fail_under: 60
+table:
+  color: always
+outputs:
+  - format: table
+  - format: json
+    path: ./coverage-report.json
+`
+	const jsonConfig = `{
+  "input": "./coverage.json",
+  "schema": "./schema.json",
+  "ignore_schemas": ["name"],
+  "fail_under": 60,
+  "table": {"color": "always"},
+  "outputs": [{"format": "table"}, {"format": "json", "path": "./coverage-report.json"}]
+}`
+	// fromConfig is the configuration of both files above.
+	fromConfig := func(cfg *config.Config) {
+		cfg.Input = "./coverage.json"
+		cfg.Schema = "./schema.json"
+		cfg.IgnoreSchemas = []string{"name"}
+		cfg.FailUnder = 60
+		cfg.Table.Color = "always"
+		cfg.Outputs = []config.Output{{Format: "table"}, {Format: "json", Path: "./coverage-report.json"}}
+	}
+
+	cases := []struct {
+		name       string
+		configFile string
+		content    string
+		args       []string
+		// expected modifies the default configuration into the expected one.
+		expected func(cfg *config.Config)
+		err      string
+	}{
+		{
+			name:     "defaults",
+			expected: func(cfg *config.Config) {},
+		},
+		{
+			name: "flags only",
+			args: []string{"-input", "./in.json", "-schema", "./s.json", "-ignore-schema", "name,location", "-format", "table,csv", "-output", "-,./out.csv"},
+			expected: func(cfg *config.Config) {
+				cfg.Input = "./in.json"
+				cfg.Schema = "./s.json"
+				cfg.IgnoreSchemas = []string{"name", "location"}
+				cfg.Outputs = []config.Output{{Format: "table", Path: "-"}, {Format: "csv", Path: "./out.csv"}}
+			},
+		},
+		{
+			name:       "yaml config only",
+			configFile: "coverage.yaml",
+			content:    yamlConfig,
+			expected:   fromConfig,
+		},
+		{
+			name:       "json config only",
+			configFile: "coverage.json",
+			content:    jsonConfig,
+			expected:   fromConfig,
+		},
+		{
+			name:       "config with explicit flags",
+			configFile: "coverage.yaml",
+			content:    yamlConfig,
+			// the flags set explicitly override the file even if they are set to the defaults.
+			args: []string{"-ignore-schema", "location", "-fail-under", "0", "-color", "auto", "-badge-dir", "./badges"},
+			expected: func(cfg *config.Config) {
+				fromConfig(cfg)
+				cfg.IgnoreSchemas = []string{"location"}
+				cfg.FailUnder = 0
+				cfg.Table.Color = "auto"
+				cfg.Badge.Dir = "./badges"
+			},
+		},
+		{
+			name:       "format overrides the outputs of config",
+			configFile: "coverage.json",
+			content:    jsonConfig,
+			args:       []string{"-format", "markdown"},
+			expected: func(cfg *config.Config) {
+				fromConfig(cfg)
+				cfg.Outputs = []config.Output{{Format: "markdown"}}
+			},
+		},
+		{
+			name:       "output pairs with the formats of config",
+			configFile: "coverage.yaml",
+			content:    yamlConfig,
+			args:       []string{"-output", "./table.txt,./coverage.json"},
+			expected: func(cfg *config.Config) {
+				fromConfig(cfg)
+				cfg.Outputs = []config.Output{{Format: "table", Path: "./table.txt"}, {Format: "json", Path: "./coverage.json"}}
+			},
+		},
+		{
+			name: "output pairs with the default format",
+			args: []string{"-output", "./coverage-report.json"},
+			expected: func(cfg *config.Config) {
+				cfg.Outputs = []config.Output{{Format: "json", Path: "./coverage-report.json"}}
+			},
+		},
+		{
+			name: "more formats than outputs",
+			args: []string{"-format", "json,csv", "-output", "./coverage.json"},
+			err:  "1 outputs are specified for 2 formats",
+		},
+		{
+			name: "more outputs than formats",
+			args: []string{"-format", "json", "-output", "./a.json,./b.json"},
+			err:  "2 outputs are specified for 1 formats",
+		},
+		{
+			name:       "outputs mismatching the formats of config",
+			configFile: "coverage.yaml",
+			content:    yamlConfig,
+			args:       []string{"-output", "./coverage.json"},
+			err:        "1 outputs are specified for 2 formats",
+		},
+		{
+			name: "portal output",
+			args: []string{"-portal-output", "-format", "table,json", "-output", "-,./portal.json"},
+			expected: func(cfg *config.Config) {
+				cfg.Outputs = []config.Output{{Format: "table", Path: "-"}, {Format: "portal", Path: "./portal.json"}}
+			},
+		},
+		{
+			name:       "portal output with config",
+			configFile: "coverage.yaml",
+			content:    yamlConfig,
+			args:       []string{"-portal-output"},
+			expected: func(cfg *config.Config) {
+				fromConfig(cfg)
+				cfg.Outputs = []config.Output{{Format: "table"}, {Format: "portal", Path: "./coverage-report.json"}}
+			},
+		},
+		{
+			name: "portal output without json output",
+			args: []string{"-portal-output", "-format", "table"},
+			err:  "there is no json output",
+		},
+		{
+			name:       "bad config",
+			configFile: "coverage.yaml",
+			content:    "outputs: table\n",
+			err:        "load config file",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := c.args
+			if c.configFile != "" {
+				path := filepath.Join(t.TempDir(), c.configFile)
+				if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+
+			fs := flag.NewFlagSet("report", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			flags := addReportFlags(fs)
+			if err := fs.Parse(args); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := flags.config()
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("error = %v, expected to contain %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := defaultConfig()
+			c.expected(&expected)
+			if !reflect.DeepEqual(cfg, expected) {
+				t.Errorf("config = %+v, expected %+v", cfg, expected)
+			}
+		})
+	}
+}
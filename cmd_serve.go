@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/config"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/server"
+)
+
+var serveCommand = command{
+	name:    "serve",
+	summary: "serve the coverage over HTTP with JSON endpoints and a web UI",
+	usage:   "[flags]",
+	description: "Serve the coverage over HTTP, with JSON endpoints for the provider summary, the resources, the property tree of a resource\n" +
+		"and the explanation of a property, together with a web UI built on them. The input and schema files are reloaded once changed.",
+	examples: []string{
+		"serve -input ./coverage.json -schema ./schema.json",
+		"serve -config ./coverage.yaml -addr :8080",
+	},
+	setup: setupServe,
+}
+
+func setupServe(fs *flag.FlagSet) func(args []string) {
+	inputs := addInputFlags(fs)
+	ignoreUncoveredResources := fs.Bool("ignore-uncovered-resources", false, "ignore uncovered resources")
+	addr := fs.String("addr", "localhost:8080", "the address to listen on")
+	watchInterval := fs.Duration("watch-interval", time.Second, "the interval to poll the input and schema files")
+
+	return func(args []string) {
+		cfg, err := inputs.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		fs.Visit(func(fl *flag.Flag) {
+			if fl.Name == "ignore-uncovered-resources" {
+				cfg.IgnoreUncoveredResources = *ignoreUncoveredResources
+			}
+		})
+
+		if err := runServe(cfg, *addr, *watchInterval); err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+func loadSnapshot(cfg config.Config) (*server.Snapshot, error) {
+	coverageMap, resources, err := loadInputs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return server.NewSnapshot(resources, coverageMap, cfg.IgnoreSchemas, cfg.IgnoreUncoveredResources)
+}
+
+// runServe serves the coverage until interrupted, the snapshot is replaced every time the input or schema file is changed.
+func runServe(cfg config.Config, addr string, interval time.Duration) error {
+	snapshot, err := loadSnapshot(cfg)
+	if err != nil {
+		return err
+	}
+	srv := server.New(snapshot)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		watcher := newFileWatcher(interval, cfg.Input, cfg.Schema)
+		for {
+			changed, err := watcher.wait(ctx)
+			if err != nil {
+				return
+			}
+			// the former snapshot is kept serving if the files fail to load, they may be in the middle of being written.
+			snapshot, err := loadSnapshot(cfg)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			srv.Update(snapshot)
+			log.Printf("reloaded %v, total percent: %.2f%%", changed, snapshot.Result.Percent())
+		}
+	}()
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+	log.Printf("serving the coverage on http://%s, press Ctrl-C to stop", addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+var validateCommand = command{
+	name:    "validate",
+	summary: "check the coverage entries against the schema",
+	usage:   "[flags]",
+	description: "Check the coverage entries against the schema, the entries of resources not in the schema, the entries matching no schema property,\n" +
+		"the keys which are not valid json pointers and the entries without any mapping are reported. It exits with 1 if any problem is found.",
+	examples: []string{
+		"validate -input ./coverage.json -schema ./schema.json",
+		"validate -input ./coverage.json -schema ./schema.json -format json",
+	},
+	setup: setupValidate,
+}
+
+type validationProblem struct {
+	Resource string `json:"resource"`
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+}
+
+func setupValidate(fs *flag.FlagSet) func(args []string) {
+	inputs := addInputFlags(fs)
+	format := fs.String("format", "text", "the output format, possible values: text, json")
+
+	return func(args []string) {
+		if len(args) != 0 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		if *format != "text" && *format != "json" {
+			exitOnError(fmt.Errorf("unknown format %q", *format))
+		}
+
+		cfg, err := inputs.config()
+		if err != nil {
+			exitOnError(err)
+		}
+		coverageMap, resources, err := loadInputs(cfg)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		problems, err := validate(resources, coverageMap, cfg.IgnoreSchemas)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if err := writeProblems(os.Stdout, problems, *format); err != nil {
+			exitOnError(err)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+// validate returns the problems of the coverage entries sorted by resource and key.
+func validate(resources map[string]jsonhelper.ResourceJSON, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage, ignoreSchemas []string) ([]validationProblem, error) {
+	problems := make([]validationProblem, 0)
+
+	// the entries which could not be run are reported and left out.
+	valid := make(map[string]map[string][]jsonhelper.PropertyCoverage)
+	for resType, entries := range coverageMap {
+		valid[resType] = make(map[string][]jsonhelper.PropertyCoverage)
+		for key, mappings := range entries {
+			if _, err := jsonpointer.New(key); err != nil {
+				problems = append(problems, validationProblem{Resource: resType, Key: key, Message: fmt.Sprintf("invalid json pointer: %v", err)})
+				continue
+			}
+			if len(mappings) == 0 {
+				problems = append(problems, validationProblem{Resource: resType, Key: key, Message: "no mapping"})
+				continue
+			}
+			valid[resType][key] = mappings
+		}
+	}
+
+	result, err := runReport(resources, valid, ignoreSchemas, true)
+	if err != nil {
+		return nil, err
+	}
+	for resType, keys := range result.Orphans {
+		if _, ok := resources[resType]; !ok {
+			problems = append(problems, validationProblem{Resource: resType, Message: fmt.Sprintf("resource not found in schema, %d entries", len(keys))})
+			continue
+		}
+		for _, key := range keys {
+			problems = append(problems, validationProblem{Resource: resType, Key: key, Message: "no schema property matched"})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Resource != problems[j].Resource {
+			return problems[i].Resource < problems[j].Resource
+		}
+		return problems[i].Key < problems[j].Key
+	})
+	return problems, nil
+}
+
+// writeProblems writes the problems in format, which is either text or json.
+func writeProblems(w io.Writer, problems []validationProblem, format string) error {
+	if format != "json" {
+		printProblems(w, problems)
+		return nil
+	}
+	b, err := json.MarshalIndent(problems, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printProblems(w io.Writer, problems []validationProblem) {
+	for _, p := range problems {
+		if p.Key != "" {
+			fmt.Fprintf(w, "%s: %s: %s\n", p.Resource, p.Key, p.Message)
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", p.Resource, p.Message)
+		}
+	}
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "no problem found")
+	} else {
+		fmt.Fprintf(w, "%d problems found\n", len(problems))
+	}
+}
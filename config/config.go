@@ -0,0 +1,126 @@
+// Package config defines the configuration file of a run, which could be written in either JSON or YAML.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// Input is the coverage file.
+	Input  string `json:"input" yaml:"input"`
+	Schema string `json:"schema" yaml:"schema"`
+	// IgnoreSchemas are the display pointers of the properties to ignore without the leading `/`, e.g. `location` or `site_config/0/always_on`,
+	// a property is also ignored if one of its coverage keys is the rule, e.g. `site_config/1/always_on`.
+	IgnoreSchemas            []string    `json:"ignore_schemas" yaml:"ignore_schemas"`
+	IgnoreUncoveredResources bool        `json:"ignore_uncovered_resources" yaml:"ignore_uncovered_resources"`
+	Diagnostics              Diagnostics `json:"diagnostics" yaml:"diagnostics"`
+	// FailUnder fails the run if the total percent is lower than it, it's not checked if it's 0.
+	FailUnder float64 `json:"fail_under" yaml:"fail_under"`
+	// ServiceOverrides is the file of the services and namespaces of resources, which take precedence over the ones derived from the mappings.
+	ServiceOverrides string   `json:"service_overrides" yaml:"service_overrides"`
+	Badge            Badge    `json:"badge" yaml:"badge"`
+	Table            Table    `json:"table" yaml:"table"`
+	Outputs          []Output `json:"outputs" yaml:"outputs"`
+}
+
+type Diagnostics struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// File is the file to write the plain-text diagnostics into, they are written to stderr if it's empty.
+	File string `json:"file" yaml:"file"`
+}
+
+type Badge struct {
+	// Dir is the directory to write badges into, badges are not written if it's empty.
+	Dir   string `json:"dir" yaml:"dir"`
+	Scope string `json:"scope" yaml:"scope"`
+	// Thresholds are the colors of badges in the format of `percent=color,...`.
+	Thresholds string `json:"thresholds" yaml:"thresholds"`
+}
+
+type Table struct {
+	Sort  string `json:"sort" yaml:"sort"`
+	Top   int    `json:"top" yaml:"top"`
+	Color string `json:"color" yaml:"color"`
+}
+
+type Output struct {
+	Format string `json:"format" yaml:"format"`
+	// Path is the file to write the output into, empty or `-` means stdout.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Load reads the configuration file into cfg, the fields not in the file are kept, so cfg could be filled with the defaults beforehand.
+// The file is decoded as JSON if its extension is `.json`, otherwise as YAML. Unknown fields are rejected.
+func Load(path string, cfg *Config) error {
+	if err := decodeFile(path, cfg); err != nil {
+		return fmt.Errorf("load config file: %v", err)
+	}
+	return nil
+}
+
+// LoadServiceOverrides reads the file of map[resourceType]Origin, in the same format as the configuration file, e.g.
+//
+//	azurerm_security_center_contact:
+//	  service: security
+//	  namespace: Microsoft.Security
+//
+// Either field could be omitted to keep the one derived from the mappings.
+func LoadServiceOverrides(path string) (map[string]coverage.Origin, error) {
+	result := make(map[string]coverage.Origin)
+	if err := decodeFile(path, &result); err != nil {
+		return nil, fmt.Errorf("load service overrides: %v", err)
+	}
+	return result, nil
+}
+
+// decodeFile decodes the file as JSON if its extension is `.json`, otherwise as YAML, unknown fields are rejected.
+func decodeFile(path string, v interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// Write writes the configuration in format, which is either `json` or `yaml`.
+func (c Config) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown config format %q", format)
+	}
+}
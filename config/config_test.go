@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+func TestLoad(t *testing.T) {
+	// the defaults which the fields not in the file are kept as.
+	defaults := Config{
+		Schema:  "./default-schema.json",
+		Badge:   Badge{Scope: "total", Thresholds: "0=red"},
+		Outputs: []Output{{Format: "json"}},
+	}
+	loaded := Config{
+		Input:         "./coverage.json",
+		Schema:        "./default-schema.json",
+		IgnoreSchemas: []string{"name", "site_config/0/always_on"},
+		FailUnder:     60,
+		Badge:         Badge{Dir: "./badges", Scope: "total", Thresholds: "0=red"},
+		Outputs:       []Output{{Format: "table"}, {Format: "html", Path: "./coverage.html"}},
+	}
+
+	cases := []struct {
+		name     string
+		file     string
+		content  string
+		expected Config
+		err      string
+	}{
+		{
+			name: "yaml",
+			file: "coverage.yaml",
+			content: `input: ./coverage.json
+ignore_schemas: [name, site_config/0/always_on]
+fail_under: 60
+badge:
+  dir: ./badges
+outputs:
+  - format: table
+  - format: html
+    path: ./coverage.html
+`,
+			expected: loaded,
+		},
+		{
+			name: "json",
+			file: "coverage.json",
+			content: `{
+  "input": "./coverage.json",
+  "ignore_schemas": ["name", "site_config/0/always_on"],
+  "fail_under": 60,
+  "badge": {"dir": "./badges"},
+  "outputs": [{"format": "table"}, {"format": "html", "path": "./coverage.html"}]
+}`,
+			expected: loaded,
+		},
+		{
+			name:     "json extension in upper case",
+			file:     "coverage.JSON",
+			content:  `{"fail_under": 60}`,
+			expected: withFailUnder(defaults, 60),
+		},
+		// any extension other than `.json` is taken as yaml, which is a superset of json.
+		{
+			name:     "yml",
+			file:     "coverage.yml",
+			content:  "fail_under: 60\n",
+			expected: withFailUnder(defaults, 60),
+		},
+		{
+			name:     "no extension",
+			file:     "coverage",
+			content:  `{"fail_under": 60}`,
+			expected: withFailUnder(defaults, 60),
+		},
+		{
+			name:     "empty yaml",
+			file:     "coverage.yaml",
+			content:  "",
+			expected: defaults,
+		},
+		{
+			name:    "unknown field in yaml",
+			file:    "coverage.yaml",
+			content: "fail_undr: 60\n",
+			err:     "field fail_undr not found",
+		},
+		{
+			name:    "unknown nested field in yaml",
+			file:    "coverage.yaml",
+			content: "badge:\n  colour: red\n",
+			err:     "field colour not found",
+		},
+		{
+			name:    "unknown field in json",
+			file:    "coverage.json",
+			content: `{"fail_undr": 60}`,
+			err:     `unknown field "fail_undr"`,
+		},
+		{
+			name:    "bad yaml",
+			file:    "coverage.yaml",
+			content: "outputs: [table\n",
+			err:     "load config file: yaml",
+		},
+		{
+			name:    "bad json",
+			file:    "coverage.json",
+			content: `{"fail_under": 60`,
+			err:     "load config file: unexpected EOF",
+		},
+		{
+			name:    "wrong type",
+			file:    "coverage.json",
+			content: `{"fail_under": "60"}`,
+			err:     "cannot unmarshal string",
+		},
+		{
+			name: "missing file",
+			file: "",
+			err:  "no such file or directory",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+			if c.file != "" {
+				path = filepath.Join(t.TempDir(), c.file)
+				if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg := defaults
+			cfg.Outputs = append([]Output(nil), defaults.Outputs...)
+			err := Load(path, &cfg)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("error = %v, expected to contain %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, c.expected) {
+				t.Errorf("config = %+v, expected %+v", cfg, c.expected)
+			}
+		})
+	}
+}
+
+func withFailUnder(cfg Config, failUnder float64) Config {
+	cfg.FailUnder = failUnder
+	return cfg
+}
+
+func TestLoadServiceOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	content := `azurerm_security_center_contact:
+  service: security
+  namespace: Microsoft.Security
+azurerm_linux_web_app:
+  service: appservice
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := LoadServiceOverrides(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]coverage.Origin{
+		"azurerm_security_center_contact": {Service: "security", Namespace: "Microsoft.Security"},
+		"azurerm_linux_web_app":           {Service: "appservice"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("overrides = %+v, expected %+v", overrides, expected)
+	}
+
+	if err := os.WriteFile(path, []byte("azurerm_linux_web_app:\n  team: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadServiceOverrides(path); err == nil || !strings.Contains(err.Error(), "field team not found") {
+		t.Errorf("error = %v, expected the unknown field rejected", err)
+	}
+}
@@ -0,0 +1,175 @@
+package coverage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+// APIVersion is an api-version of azure-rest-api-specs, e.g. `2022-09-01` of `specification/web/resource-manager/Microsoft.Web/stable/2022-09-01/...`.
+type APIVersion struct {
+	Version string `json:"version"`
+	// Preview is whether the version is under the `preview` folder or has a `-preview` suffix.
+	Preview bool `json:"preview"`
+}
+
+func (v APIVersion) String() string {
+	if v.Preview && !strings.Contains(v.Version, "preview") {
+		return v.Version + " (preview)"
+	}
+	return v.Version
+}
+
+// apiVersionLess sorts the versions from the oldest, the versions of the same date are sorted lexically, e.g. `2021-01-01` < `2021-01-01-preview`.
+func apiVersionLess(a, b APIVersion) bool {
+	if a.Version != b.Version {
+		return a.Version < b.Version
+	}
+	return !a.Preview && b.Preview
+}
+
+var specVersionRegex = regexp.MustCompile(`specification/[^/]+/(?:resource-manager|data-plane)/[^/]+/(stable|preview)/([^/]+)/`)
+
+// ParseAPIVersion returns the api-version of a link to azure-rest-api-specs, it's not found if the link has no `stable` or `preview` folder.
+func ParseAPIVersion(link string) (APIVersion, bool) {
+	m := specVersionRegex.FindStringSubmatch(link)
+	if m == nil {
+		return APIVersion{}, false
+	}
+	return APIVersion{
+		Version: m[2],
+		Preview: m[1] == "preview" || strings.Contains(m[2], "-preview"),
+	}, true
+}
+
+// mappingLink returns the link of a mapping to azure-rest-api-specs, which is `link_github`, or `ref` if the former is empty.
+func mappingLink(m jsonhelper.PropertyCoverage) string {
+	if m.LinkGithub != "" {
+		return m.LinkGithub
+	}
+	return m.Ref
+}
+
+// APIVersions returns the api-versions the mappings link to, sorted from the oldest without duplicates.
+func APIVersions(mappings []jsonhelper.PropertyCoverage) []APIVersion {
+	found := make(map[APIVersion]bool)
+	for _, m := range mappings {
+		if v, ok := ParseAPIVersion(mappingLink(m)); ok {
+			found[v] = true
+		}
+	}
+
+	result := make([]APIVersion, 0, len(found))
+	for v := range found {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return apiVersionLess(result[i], result[j])
+	})
+	return result
+}
+
+type VersionCount struct {
+	APIVersion
+	// PropertyCnt is the count of the properties mapped to the version.
+	PropertyCnt int `json:"property_cnt"`
+}
+
+// ResourceVersions is the api-version usage of a resource.
+type ResourceVersions struct {
+	Name string `json:"name"`
+	// Versions are the api-versions the mappings of the resource link to, sorted from the oldest.
+	Versions []VersionCount `json:"versions"`
+	// Mixed is whether the mappings of the resource link to more than one api-version.
+	Mixed bool `json:"mixed"`
+	// PreviewOnly are the properties mapped only to preview api-versions, sorted by pointer.
+	PreviewOnly []string `json:"preview_only"`
+}
+
+type ProviderVersion struct {
+	APIVersion
+	// Resources are the resources whose mappings link to the version, sorted by name.
+	Resources []string `json:"resources"`
+}
+
+type VersionUsage struct {
+	// Resources are the resources whose mappings link to any api-version, sorted by name.
+	Resources []ResourceVersions `json:"resources"`
+	// Versions are all the api-versions in use across the provider, sorted from the oldest.
+	Versions []ProviderVersion `json:"versions"`
+}
+
+// MixedCnt returns the count of resources whose mappings link to more than one api-version.
+func (u VersionUsage) MixedCnt() int {
+	cnt := 0
+	for _, res := range u.Resources {
+		if res.Mixed {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// PreviewOnlyCnt returns the count of properties mapped only to preview api-versions.
+func (u VersionUsage) PreviewOnlyCnt() int {
+	cnt := 0
+	for _, res := range u.Resources {
+		cnt += len(res.PreviewOnly)
+	}
+	return cnt
+}
+
+// VersionUsage returns the api-versions referenced by the mappings of the covered properties, the ignored properties are skipped.
+func (r *Result) VersionUsage() VersionUsage {
+	result := VersionUsage{
+		Resources: make([]ResourceVersions, 0),
+		Versions:  make([]ProviderVersion, 0),
+	}
+	// map[APIVersion][]resourceType
+	providerVersions := make(map[APIVersion][]string)
+	for _, res := range r.SortedResources() {
+		counts := make(map[APIVersion]int)
+		previewOnly := make([]string, 0)
+		for _, p := range res.SortedProperties() {
+			if p.Ignored() || len(p.APIVersions) == 0 {
+				continue
+			}
+			preview := true
+			for _, v := range p.APIVersions {
+				counts[v]++
+				preview = preview && v.Preview
+			}
+			if preview {
+				previewOnly = append(previewOnly, p.Pointer)
+			}
+		}
+		if len(counts) == 0 {
+			continue
+		}
+
+		rv := ResourceVersions{
+			Name:        res.Name,
+			Versions:    make([]VersionCount, 0, len(counts)),
+			Mixed:       len(counts) > 1,
+			PreviewOnly: previewOnly,
+		}
+		for v, cnt := range counts {
+			rv.Versions = append(rv.Versions, VersionCount{APIVersion: v, PropertyCnt: cnt})
+			providerVersions[v] = append(providerVersions[v], res.Name)
+		}
+		sort.Slice(rv.Versions, func(i, j int) bool {
+			return apiVersionLess(rv.Versions[i].APIVersion, rv.Versions[j].APIVersion)
+		})
+		result.Resources = append(result.Resources, rv)
+	}
+
+	for v, resources := range providerVersions {
+		result.Versions = append(result.Versions, ProviderVersion{APIVersion: v, Resources: resources})
+	}
+	sort.Slice(result.Versions, func(i, j int) bool {
+		return apiVersionLess(result.Versions[i].APIVersion, result.Versions[j].APIVersion)
+	})
+	return result
+}
@@ -0,0 +1,110 @@
+package coverage
+
+import (
+	"sort"
+)
+
+// ResourceDelta is the change of a resource between two results, the property lists are sorted lexically.
+type ResourceDelta struct {
+	Name string `json:"name"`
+	Base Totals `json:"base"`
+	Head Totals `json:"head"`
+	// NewlyCovered are the properties covered in head but not in base, including the ones not counted in base.
+	NewlyCovered []string `json:"newly_covered,omitempty"`
+	// NoLongerCovered are the properties covered in base but not in head, including the ones not counted in head.
+	NoLongerCovered []string `json:"no_longer_covered,omitempty"`
+	// Added and Removed are the uncovered properties only counted in head or base, e.g. the schema or the ignore rules are changed.
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+func (d ResourceDelta) Changed() bool {
+	return d.Base != d.Head || len(d.NewlyCovered) > 0 || len(d.NoLongerCovered) > 0 || len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+type Delta struct {
+	Base Totals `json:"base"`
+	Head Totals `json:"head"`
+	// Resources are the changed resources sorted by name.
+	Resources []ResourceDelta `json:"resources"`
+}
+
+// Diff compares the results of two runs, only the changed resources are included.
+func Diff(base, head *Result) Delta {
+	names := make(map[string]bool)
+	for name := range base.Resources {
+		names[name] = true
+	}
+	for name := range head.Resources {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	result := Delta{
+		Base:      base.Totals,
+		Head:      head.Totals,
+		Resources: make([]ResourceDelta, 0),
+	}
+	for _, name := range sorted {
+		if d := diffResource(name, base.Resources[name], head.Resources[name]); d.Changed() {
+			result.Resources = append(result.Resources, d)
+		}
+	}
+	return result
+}
+
+// diffResource compares a resource, either of base and head is nil if the resource is not in that result.
+// The ignored properties are not compared, as they are not counted.
+func diffResource(name string, base, head *Resource) ResourceDelta {
+	if base == nil {
+		base = &Resource{Name: name}
+	}
+	if head == nil {
+		head = &Resource{Name: name}
+	}
+
+	d := ResourceDelta{
+		Name: name,
+		Base: base.Totals,
+		Head: head.Totals,
+	}
+	for ptr, hp := range head.Properties {
+		if hp.Ignored() {
+			continue
+		}
+		bp, ok := base.Properties[ptr]
+		if !ok || bp.Ignored() {
+			bp = &Property{}
+			if !hp.Covered {
+				d.Added = append(d.Added, ptr)
+			}
+		}
+		switch {
+		case hp.Covered && !bp.Covered:
+			d.NewlyCovered = append(d.NewlyCovered, ptr)
+		case !hp.Covered && bp.Covered:
+			d.NoLongerCovered = append(d.NoLongerCovered, ptr)
+		}
+	}
+	for ptr, bp := range base.Properties {
+		if bp.Ignored() {
+			continue
+		}
+		if hp, ok := head.Properties[ptr]; !ok || hp.Ignored() {
+			if bp.Covered {
+				d.NoLongerCovered = append(d.NoLongerCovered, ptr)
+			} else {
+				d.Removed = append(d.Removed, ptr)
+			}
+		}
+	}
+	sort.Strings(d.NewlyCovered)
+	sort.Strings(d.NoLongerCovered)
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
@@ -0,0 +1,97 @@
+package coverage
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := NewResult()
+	base.AddProperty("azurerm_same", covered("/name"))
+	base.AddProperty("azurerm_removed", covered("/name"))
+	base.AddProperty("azurerm_removed", uncovered("/tags"))
+	base.AddProperty("azurerm_changed", covered("/name"))
+	base.AddProperty("azurerm_changed", uncovered("/tags"))
+	base.AddProperty("azurerm_changed", covered("/zones"))
+	base.AddProperty("azurerm_changed", uncovered("/comment"))
+	base.AddProperty("azurerm_changed", covered("/location"))
+	base.AddProperty("azurerm_changed", uncovered("/identity"))
+
+	head := NewResult()
+	head.AddProperty("azurerm_same", covered("/name"))
+	head.AddProperty("azurerm_added", uncovered("/name"))
+	head.AddProperty("azurerm_changed", covered("/name"))
+	head.AddProperty("azurerm_changed", covered("/tags"))
+	head.AddProperty("azurerm_changed", uncovered("/zones"))
+	head.AddProperty("azurerm_changed", uncovered("/site_config/0/always_on"))
+	head.AddProperty("azurerm_changed", covered("/site_config/0/http2_enabled"))
+	// the ignored properties are not compared, so they are removed from the counts.
+	head.AddProperty("azurerm_changed", ignored("/location"))
+	head.AddProperty("azurerm_changed", ignored("/identity"))
+
+	expected := []ResourceDelta{
+		{
+			Name:  "azurerm_added",
+			Head:  Totals{TotalCnt: 1},
+			Added: []string{"/name"},
+		},
+		{
+			Name:            "azurerm_changed",
+			Base:            Totals{TotalCnt: 6, CoveredCnt: 3},
+			Head:            Totals{TotalCnt: 5, CoveredCnt: 3, IgnoredCnt: 2},
+			NewlyCovered:    []string{"/site_config/0/http2_enabled", "/tags"},
+			NoLongerCovered: []string{"/location", "/zones"},
+			Added:           []string{"/site_config/0/always_on"},
+			Removed:         []string{"/comment", "/identity"},
+		},
+		{
+			Name:            "azurerm_removed",
+			Base:            Totals{TotalCnt: 2, CoveredCnt: 1},
+			NoLongerCovered: []string{"/name"},
+			Removed:         []string{"/tags"},
+		},
+	}
+	delta := Diff(base, head)
+	if !reflect.DeepEqual(delta.Resources, expected) {
+		t.Errorf("resources = %+v\nexpected %+v", delta.Resources, expected)
+	}
+
+	if delta.Base != base.Totals || delta.Head != head.Totals {
+		t.Errorf("totals = %+v -> %+v, expected %+v -> %+v", delta.Base, delta.Head, base.Totals, head.Totals)
+	}
+	// 5 of 9 (55.56%) -> 4 of 7 (57.14%)
+	if change := delta.Head.Percent() - delta.Base.Percent(); math.Abs(change-1.587) > 0.001 {
+		t.Errorf("percent change = %v, expected 1.587", change)
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	delta := Diff(testResult(), testResult())
+	if len(delta.Resources) != 0 {
+		t.Errorf("resources = %+v, expected none", delta.Resources)
+	}
+	if delta.Base != delta.Head {
+		t.Errorf("totals = %+v -> %+v", delta.Base, delta.Head)
+	}
+}
+
+func TestResourceDeltaChanged(t *testing.T) {
+	cases := []struct {
+		delta    ResourceDelta
+		expected bool
+	}{
+		{ResourceDelta{}, false},
+		{ResourceDelta{Base: Totals{TotalCnt: 1}, Head: Totals{TotalCnt: 1}}, false},
+		// the totals are the same, but a covered property is replaced by another.
+		{ResourceDelta{NewlyCovered: []string{"/a"}, NoLongerCovered: []string{"/b"}}, true},
+		{ResourceDelta{Added: []string{"/a"}}, true},
+		{ResourceDelta{Removed: []string{"/a"}}, true},
+		{ResourceDelta{Base: Totals{IgnoredCnt: 1}}, true},
+	}
+	for i, c := range cases {
+		if actual := c.delta.Changed(); actual != c.expected {
+			t.Errorf("%d: Changed = %t, expected %t", i, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package coverage defines the result of a coverage run, main and the renderers build on it, and so could the services embedding the runner.
+package coverage
+
+import (
+	"sort"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+type Totals struct {
+	TotalCnt   int `json:"total_cnt"`
+	CoveredCnt int `json:"covered_cnt"`
+	// IgnoredCnt is the count of ignored properties, which are not counted in TotalCnt.
+	IgnoredCnt int `json:"ignored_cnt"`
+}
+
+func (t Totals) UncoveredCnt() int {
+	return t.TotalCnt - t.CoveredCnt
+}
+
+// Percent returns the covered percent, it's 0 if there is no property.
+func (t Totals) Percent() float64 {
+	return Percent(t.CoveredCnt, t.TotalCnt)
+}
+
+func (t *Totals) add(p *Property) {
+	switch {
+	case p.Ignored():
+		t.IgnoredCnt++
+	case p.Covered:
+		t.TotalCnt++
+		t.CoveredCnt++
+	default:
+		t.TotalCnt++
+	}
+}
+
+type Property struct {
+	// Pointer is the display pointer of the property, the indexes of blocks are folded into `0`, e.g. `/site_config/0/always_on`.
+	Pointer    string `json:"pointer"`
+	SchemaType string `json:"schema_type"`
+	Covered    bool   `json:"covered"`
+	// Coverage is the first mapping of the first coverage key, it's nil for uncovered and ignored properties.
+	Coverage *jsonhelper.PropertyCoverage `json:"coverage,omitempty"`
+	// CoverageKeys are the coverage entries matched under all the indexes, sorted lexically.
+	CoverageKeys []string `json:"coverage_keys,omitempty"`
+	// Mappings are the mappings of all the coverage keys in the order of the keys, they're summarized by Coverage and MappingCnt in json.
+	Mappings []jsonhelper.PropertyCoverage `json:"-"`
+	// MappingCnt is the count of mappings of all the coverage keys.
+	MappingCnt int `json:"mapping_cnt"`
+	// APIVersions are the api-versions which the mappings of all the coverage keys link to, sorted from the oldest.
+	APIVersions  []APIVersion `json:"api_versions,omitempty"`
+	IgnoreReason string       `json:"ignore_reason,omitempty"`
+}
+
+func (p *Property) Ignored() bool {
+	return p.IgnoreReason != ""
+}
+
+type Resource struct {
+	Name string `json:"name"`
+	Totals
+	// EntryCnt is the count of coverage entries of the resource.
+	EntryCnt int `json:"entry_cnt"`
+	// map[displayPointer]Property, ignored properties are included.
+	Properties map[string]*Property `json:"properties"`
+}
+
+// SortedProperties returns all the properties including the ignored ones, sorted by pointer.
+func (res *Resource) SortedProperties() []*Property {
+	result := make([]*Property, 0, len(res.Properties))
+	for _, p := range res.Properties {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Pointer < result[j].Pointer
+	})
+	return result
+}
+
+// Issue is a resource whose covered count differs from its count of coverage entries,
+// which means some entries match no property, or several entries match the same property.
+type Issue struct {
+	Name       string `json:"name"`
+	CoveredCnt int    `json:"covered_cnt"`
+	EntryCnt   int    `json:"entry_cnt"`
+}
+
+type Result struct {
+	// Totals of the provider
+	Totals
+	// map[resourceType]Resource
+	Resources map[string]*Resource `json:"resources"`
+	// map[resourceType][]coverageKey of the coverage entries which match no schema property,
+	// entries of resources which are not in the schema are included.
+	Orphans map[string][]string `json:"orphans"`
+}
+
+func NewResult() *Result {
+	return &Result{
+		Resources: make(map[string]*Resource),
+		Orphans:   make(map[string][]string),
+	}
+}
+
+// AddProperty adds a property into the resource and counts it into the totals of both the resource and the provider.
+func (r *Result) AddProperty(resType string, p *Property) {
+	res, ok := r.Resources[resType]
+	if !ok {
+		res = &Resource{
+			Name:       resType,
+			Properties: make(map[string]*Property),
+		}
+		r.Resources[resType] = res
+	}
+	res.Properties[p.Pointer] = p
+	res.Totals.add(p)
+	r.Totals.add(p)
+}
+
+// Property returns the property of the display pointer.
+func (r *Result) Property(resType, ptrStr string) (*Property, bool) {
+	res, ok := r.Resources[resType]
+	if !ok {
+		return nil, false
+	}
+	p, ok := res.Properties[ptrStr]
+	return p, ok
+}
+
+// SortedResources returns the resources sorted by name.
+func (r *Result) SortedResources() []*Resource {
+	result := make([]*Resource, 0, len(r.Resources))
+	for _, res := range r.Resources {
+		result = append(result, res)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Issues returns the resources whose covered count differs from the count of coverage entries, sorted by name.
+func (r *Result) Issues() []Issue {
+	result := make([]Issue, 0)
+	for _, res := range r.SortedResources() {
+		if res.CoveredCnt != res.EntryCnt {
+			result = append(result, Issue{
+				Name:       res.Name,
+				CoveredCnt: res.CoveredCnt,
+				EntryCnt:   res.EntryCnt,
+			})
+		}
+	}
+	return result
+}
+
+// Update returns a copy of r in which the resources of names are replaced by the ones of partial, which is the result of a run of only those resources.
+// The resources of names not in partial are removed, the totals are counted again.
+func (r *Result) Update(names []string, partial *Result) *Result {
+	replaced := make(map[string]bool)
+	for _, name := range names {
+		replaced[name] = true
+	}
+
+	result := NewResult()
+	for name, res := range r.Resources {
+		if !replaced[name] {
+			result.Resources[name] = res
+		}
+	}
+	for name, keys := range r.Orphans {
+		if !replaced[name] {
+			result.Orphans[name] = keys
+		}
+	}
+	for _, name := range names {
+		if res, ok := partial.Resources[name]; ok {
+			result.Resources[name] = res
+		}
+		if keys, ok := partial.Orphans[name]; ok {
+			result.Orphans[name] = keys
+		}
+	}
+
+	for _, res := range result.Resources {
+		result.TotalCnt += res.TotalCnt
+		result.CoveredCnt += res.CoveredCnt
+		result.IgnoredCnt += res.IgnoredCnt
+	}
+	return result
+}
+
+func Percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
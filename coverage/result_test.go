@@ -0,0 +1,195 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func covered(ptr string) *Property {
+	return &Property{Pointer: ptr, Covered: true, CoverageKeys: []string{ptr}, MappingCnt: 1}
+}
+
+func uncovered(ptr string) *Property {
+	return &Property{Pointer: ptr}
+}
+
+func ignored(ptr string) *Property {
+	return &Property{Pointer: ptr, IgnoreReason: "ignored by `name`"}
+}
+
+// testResult returns a result of three resources, azurerm_b has an ignored property and azurerm_c has no covered property.
+func testResult() *Result {
+	r := NewResult()
+	r.AddProperty("azurerm_b", covered("/name"))
+	r.AddProperty("azurerm_b", uncovered("/tags"))
+	r.AddProperty("azurerm_b", ignored("/location"))
+	r.AddProperty("azurerm_a", covered("/name"))
+	r.AddProperty("azurerm_a", covered("/site_config/0/always_on"))
+	r.AddProperty("azurerm_c", uncovered("/name"))
+	return r
+}
+
+func TestAddProperty(t *testing.T) {
+	r := testResult()
+	cases := []struct {
+		name     string
+		expected Totals
+	}{
+		{"azurerm_a", Totals{TotalCnt: 2, CoveredCnt: 2}},
+		// the ignored properties are counted in IgnoredCnt only.
+		{"azurerm_b", Totals{TotalCnt: 2, CoveredCnt: 1, IgnoredCnt: 1}},
+		{"azurerm_c", Totals{TotalCnt: 1}},
+	}
+	for _, c := range cases {
+		res, ok := r.Resources[c.name]
+		if !ok {
+			t.Fatalf("%s not found", c.name)
+		}
+		if res.Totals != c.expected {
+			t.Errorf("totals of %s = %+v, expected %+v", c.name, res.Totals, c.expected)
+		}
+	}
+
+	// the totals of the provider are the sum of the resources.
+	if expected := (Totals{TotalCnt: 5, CoveredCnt: 3, IgnoredCnt: 1}); r.Totals != expected {
+		t.Errorf("totals = %+v, expected %+v", r.Totals, expected)
+	}
+	if cnt := r.UncoveredCnt(); cnt != 2 {
+		t.Errorf("UncoveredCnt = %d, expected 2", cnt)
+	}
+	if p := r.Percent(); p != 60 {
+		t.Errorf("Percent = %v, expected 60", p)
+	}
+	if p := NewResult().Percent(); p != 0 {
+		t.Errorf("Percent of an empty result = %v, expected 0", p)
+	}
+}
+
+func TestIgnoredProperty(t *testing.T) {
+	r := testResult()
+	p, ok := r.Property("azurerm_b", "/location")
+	if !ok {
+		t.Fatal("/location of azurerm_b not found")
+	}
+	if !p.Ignored() {
+		t.Errorf("/location is not ignored")
+	}
+	for _, ptr := range []string{"/name", "/tags"} {
+		if p, _ := r.Property("azurerm_b", ptr); p.Ignored() {
+			t.Errorf("%s is ignored", ptr)
+		}
+	}
+
+	// the ignored properties are still listed.
+	pointers := make([]string, 0)
+	for _, p := range r.Resources["azurerm_b"].SortedProperties() {
+		pointers = append(pointers, p.Pointer)
+	}
+	if expected := []string{"/location", "/name", "/tags"}; !reflect.DeepEqual(pointers, expected) {
+		t.Errorf("properties of azurerm_b = %v, expected %v", pointers, expected)
+	}
+}
+
+func TestProperty(t *testing.T) {
+	r := testResult()
+	cases := []struct {
+		resType string
+		ptr     string
+		found   bool
+	}{
+		{"azurerm_a", "/site_config/0/always_on", true},
+		{"azurerm_a", "/tags", false},
+		{"azurerm_not_found", "/name", false},
+	}
+	for _, c := range cases {
+		p, ok := r.Property(c.resType, c.ptr)
+		if ok != c.found {
+			t.Errorf("Property(%s, %s) found = %t, expected %t", c.resType, c.ptr, ok, c.found)
+			continue
+		}
+		if ok && p.Pointer != c.ptr {
+			t.Errorf("Property(%s, %s) = %s", c.resType, c.ptr, p.Pointer)
+		}
+	}
+}
+
+func TestSortedResources(t *testing.T) {
+	names := make([]string, 0)
+	for _, res := range testResult().SortedResources() {
+		names = append(names, res.Name)
+	}
+	if expected := []string{"azurerm_a", "azurerm_b", "azurerm_c"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("SortedResources = %v, expected %v", names, expected)
+	}
+}
+
+func TestIssues(t *testing.T) {
+	r := testResult()
+	// azurerm_a has an entry which matches no property, azurerm_b has two entries matching the same property.
+	r.Resources["azurerm_a"].EntryCnt = 3
+	r.Resources["azurerm_b"].EntryCnt = 2
+	r.Resources["azurerm_c"].EntryCnt = 0
+
+	expected := []Issue{
+		{Name: "azurerm_a", CoveredCnt: 2, EntryCnt: 3},
+		{Name: "azurerm_b", CoveredCnt: 1, EntryCnt: 2},
+	}
+	if issues := r.Issues(); !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Issues = %+v, expected %+v", issues, expected)
+	}
+
+	r.Resources["azurerm_a"].EntryCnt = 2
+	r.Resources["azurerm_b"].EntryCnt = 1
+	if issues := r.Issues(); len(issues) != 0 {
+		t.Errorf("Issues = %+v, expected none", issues)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	base := testResult()
+	base.Orphans["azurerm_a"] = []string{"/not_a_property"}
+	base.Orphans["azurerm_b"] = []string{"/not_a_property"}
+	base.Orphans["azurerm_not_in_schema"] = []string{"/name"}
+
+	// azurerm_a is changed, azurerm_c is removed and azurerm_d is added.
+	partial := NewResult()
+	partial.AddProperty("azurerm_a", covered("/name"))
+	partial.AddProperty("azurerm_d", uncovered("/name"))
+	partial.Orphans["azurerm_d"] = []string{"/not_a_property"}
+
+	updated := base.Update([]string{"azurerm_a", "azurerm_c", "azurerm_d"}, partial)
+
+	names := make([]string, 0)
+	for _, res := range updated.SortedResources() {
+		names = append(names, res.Name)
+	}
+	if expected := []string{"azurerm_a", "azurerm_b", "azurerm_d"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("resources = %v, expected %v", names, expected)
+	}
+	if updated.Resources["azurerm_a"] != partial.Resources["azurerm_a"] {
+		t.Errorf("azurerm_a is not replaced")
+	}
+	if updated.Resources["azurerm_b"] != base.Resources["azurerm_b"] {
+		t.Errorf("azurerm_b is replaced")
+	}
+
+	// the orphans of the names are replaced, even if the partial run has none.
+	expectedOrphans := map[string][]string{
+		"azurerm_b":             {"/not_a_property"},
+		"azurerm_d":             {"/not_a_property"},
+		"azurerm_not_in_schema": {"/name"},
+	}
+	if !reflect.DeepEqual(updated.Orphans, expectedOrphans) {
+		t.Errorf("orphans = %v, expected %v", updated.Orphans, expectedOrphans)
+	}
+
+	// a: 1 of 1, b: 1 of 2 and 1 ignored, d: 0 of 1.
+	if expected := (Totals{TotalCnt: 4, CoveredCnt: 2, IgnoredCnt: 1}); updated.Totals != expected {
+		t.Errorf("totals = %+v, expected %+v", updated.Totals, expected)
+	}
+
+	// base is not changed.
+	if expected := (Totals{TotalCnt: 5, CoveredCnt: 3, IgnoredCnt: 1}); base.Totals != expected || len(base.Resources) != 3 || len(base.Orphans) != 3 {
+		t.Errorf("base is changed by Update")
+	}
+}
@@ -0,0 +1,129 @@
+package coverage
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	GroupByService   = "service"
+	GroupByNamespace = "namespace"
+)
+
+// UnknownOrigin is the service or namespace of the resources whose mappings link to no spec.
+const UnknownOrigin = "unknown"
+
+// Origin is where a resource is specified in azure-rest-api-specs, e.g. the service `security` and the namespace `Microsoft.Security`
+// of `specification/security/resource-manager/Microsoft.Security/stable/2019-01-01/...`.
+type Origin struct {
+	Service   string `json:"service" yaml:"service"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+}
+
+var (
+	specServiceRegex   = regexp.MustCompile(`specification/([^/]+)/`)
+	specNamespaceRegex = regexp.MustCompile(`specification/[^/]+/(?:resource-manager|data-plane)/([^/]+)/`)
+)
+
+// ResourceOrigin returns the origin which the most mappings of all the coverage keys of the covered properties link to,
+// the lexically smaller one wins a tie. The link of a mapping is `link_github`, or `ref` if the former is empty.
+// It's UnknownOrigin if no mapping links to a spec.
+func ResourceOrigin(res *Resource) Origin {
+	counts := make(map[Origin]int)
+	for _, p := range res.Properties {
+		for _, mapping := range p.Mappings {
+			link := mappingLink(mapping)
+			m := specServiceRegex.FindStringSubmatch(link)
+			if m == nil {
+				continue
+			}
+			origin := Origin{Service: m[1], Namespace: UnknownOrigin}
+			if m := specNamespaceRegex.FindStringSubmatch(link); m != nil {
+				origin.Namespace = m[1]
+			}
+			counts[origin]++
+		}
+	}
+
+	result := Origin{Service: UnknownOrigin, Namespace: UnknownOrigin}
+	maxCnt := 0
+	for origin, cnt := range counts {
+		if cnt > maxCnt || (cnt == maxCnt && originLess(origin, result)) {
+			result, maxCnt = origin, cnt
+		}
+	}
+	return result
+}
+
+func originLess(a, b Origin) bool {
+	if a.Service != b.Service {
+		return a.Service < b.Service
+	}
+	return a.Namespace < b.Namespace
+}
+
+// Origins returns map[resourceType]Origin of all the resources, the non-empty fields of overrides take precedence over the derived ones.
+func (r *Result) Origins(overrides map[string]Origin) map[string]Origin {
+	result := make(map[string]Origin)
+	for name, res := range r.Resources {
+		origin := ResourceOrigin(res)
+		if o, ok := overrides[name]; ok {
+			if o.Service != "" {
+				origin.Service = o.Service
+			}
+			if o.Namespace != "" {
+				origin.Namespace = o.Namespace
+			}
+		}
+		result[name] = origin
+	}
+	return result
+}
+
+// Group is the sum of the resources of a service or namespace.
+type Group struct {
+	Name string `json:"name"`
+	Totals
+	// Resources are the names of the resources in the group, sorted lexically.
+	Resources []string `json:"resources"`
+}
+
+// Rollup sums the totals of the resources by the service or namespace of their origins, the groups are sorted by name.
+// The resources not in origins are grouped into UnknownOrigin.
+func (r *Result) Rollup(by string, origins map[string]Origin) ([]Group, error) {
+	if by != GroupByService && by != GroupByNamespace {
+		return nil, fmt.Errorf("unknown rollup %q, possible values: %s, %s", by, GroupByService, GroupByNamespace)
+	}
+
+	groups := make(map[string]*Group)
+	for _, res := range r.SortedResources() {
+		origin, ok := origins[res.Name]
+		if !ok {
+			origin = Origin{Service: UnknownOrigin, Namespace: UnknownOrigin}
+		}
+		name := origin.Service
+		if by == GroupByNamespace {
+			name = origin.Namespace
+		}
+
+		g, ok := groups[name]
+		if !ok {
+			g = &Group{Name: name, Resources: make([]string, 0)}
+			groups[name] = g
+		}
+		g.TotalCnt += res.TotalCnt
+		g.CoveredCnt += res.CoveredCnt
+		g.IgnoredCnt += res.IgnoredCnt
+		g.Resources = append(g.Resources, res.Name)
+	}
+
+	result := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
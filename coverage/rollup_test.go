@@ -0,0 +1,140 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+const (
+	webLink      = "https://github.com/Azure/azure-rest-api-specs/blob/main/specification/web/resource-manager/Microsoft.Web/stable/2022-09-01/WebApps.json#L40"
+	storageLink  = "https://github.com/Azure/azure-rest-api-specs/blob/main/specification/storage/resource-manager/Microsoft.Storage/stable/2023-01-01/storage.json#L10"
+	securityRef  = "specification/security/resource-manager/Microsoft.Security/stable/2019-01-01/security.json#/definitions/Setting"
+	dataPlaneRef = "specification/keyvault/data-plane/Microsoft.KeyVault/stable/7.4/keys.json#/definitions/Key"
+	// a link to a spec without the resource-manager or data-plane folder has a service but no namespace.
+	readmeLink = "https://github.com/Azure/azure-rest-api-specs/blob/main/specification/cosmos-db/readme.md"
+)
+
+// mapped returns a covered property with a mapping of each link, Coverage is the first mapping as the runner sets it.
+func mapped(ptr string, links ...string) *Property {
+	p := covered(ptr)
+	for _, link := range links {
+		p.Mappings = append(p.Mappings, jsonhelper.PropertyCoverage{Addr: ptr, LinkGithub: link})
+	}
+	p.Coverage = &p.Mappings[0]
+	p.MappingCnt = len(p.Mappings)
+	return p
+}
+
+func newResource(props ...*Property) *Resource {
+	r := NewResult()
+	for _, p := range props {
+		r.AddProperty("azurerm_test", p)
+	}
+	return r.Resources["azurerm_test"]
+}
+
+func TestResourceOrigin(t *testing.T) {
+	web := Origin{Service: "web", Namespace: "Microsoft.Web"}
+	storage := Origin{Service: "storage", Namespace: "Microsoft.Storage"}
+	unknown := Origin{Service: UnknownOrigin, Namespace: UnknownOrigin}
+
+	refOnly := covered("/setting")
+	refOnly.Mappings = []jsonhelper.PropertyCoverage{{Addr: "setting", Ref: securityRef}}
+	bothLinks := covered("/name")
+	bothLinks.Mappings = []jsonhelper.PropertyCoverage{{Addr: "name", LinkGithub: webLink, Ref: securityRef}}
+	noLink := covered("/name")
+	noLink.Mappings = []jsonhelper.PropertyCoverage{{Addr: "name"}}
+
+	cases := []struct {
+		name     string
+		res      *Resource
+		expected Origin
+	}{
+		{"most mappings", newResource(mapped("/name", webLink), mapped("/tags", webLink), mapped("/kind", storageLink)), web},
+		// the mappings of all the coverage keys are counted, not only the first one.
+		{"all mappings", newResource(mapped("/name", storageLink), mapped("/kind", storageLink), mapped("/site_config/0/always_on", webLink, webLink, webLink)), web},
+		{"tie", newResource(mapped("/name", webLink), mapped("/kind", storageLink)), storage},
+		{"tie of all mappings", newResource(mapped("/name", webLink, storageLink)), storage},
+		{"ref fallback", newResource(refOnly), Origin{Service: "security", Namespace: "Microsoft.Security"}},
+		{"link_github over ref", newResource(bothLinks), web},
+		{"data plane", newResource(mapped("/key", "https://github.com/Azure/azure-rest-api-specs/blob/main/"+dataPlaneRef)), Origin{Service: "keyvault", Namespace: "Microsoft.KeyVault"}},
+		{"no namespace", newResource(mapped("/name", readmeLink)), Origin{Service: "cosmos-db", Namespace: UnknownOrigin}},
+		{"no link", newResource(noLink), unknown},
+		// the uncovered and ignored properties have no mapping.
+		{"no covered property", newResource(uncovered("/name"), ignored("/location")), unknown},
+	}
+	for _, c := range cases {
+		if actual := ResourceOrigin(c.res); actual != c.expected {
+			t.Errorf("%s: ResourceOrigin = %+v, expected %+v", c.name, actual, c.expected)
+		}
+	}
+}
+
+func testRollupResult() *Result {
+	r := NewResult()
+	r.AddProperty("azurerm_web_app", mapped("/name", webLink))
+	r.AddProperty("azurerm_web_app", uncovered("/tags"))
+	r.AddProperty("azurerm_web_slot", mapped("/name", webLink))
+	r.AddProperty("azurerm_storage_account", mapped("/name", storageLink))
+	r.AddProperty("azurerm_storage_account", ignored("/location"))
+	r.AddProperty("azurerm_unlinked", uncovered("/name"))
+	return r
+}
+
+func TestOrigins(t *testing.T) {
+	origins := testRollupResult().Origins(map[string]Origin{
+		// only the non-empty fields are overridden.
+		"azurerm_web_app":  {Service: "appservice"},
+		"azurerm_web_slot": {Namespace: "Microsoft.Sites"},
+		"azurerm_unlinked": {Service: "misc", Namespace: "Microsoft.Misc"},
+		// the overrides of resources not in the result are skipped.
+		"azurerm_not_found": {Service: "web"},
+	})
+	expected := map[string]Origin{
+		"azurerm_web_app":         {Service: "appservice", Namespace: "Microsoft.Web"},
+		"azurerm_web_slot":        {Service: "web", Namespace: "Microsoft.Sites"},
+		"azurerm_storage_account": {Service: "storage", Namespace: "Microsoft.Storage"},
+		"azurerm_unlinked":        {Service: "misc", Namespace: "Microsoft.Misc"},
+	}
+	if !reflect.DeepEqual(origins, expected) {
+		t.Errorf("Origins = %+v, expected %+v", origins, expected)
+	}
+}
+
+func TestRollup(t *testing.T) {
+	r := testRollupResult()
+	origins := r.Origins(nil)
+	delete(origins, "azurerm_web_slot")
+
+	groups, err := r.Rollup(GroupByService, origins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Group{
+		{Name: "storage", Totals: Totals{TotalCnt: 1, CoveredCnt: 1, IgnoredCnt: 1}, Resources: []string{"azurerm_storage_account"}},
+		// the resources without links, or not in origins, are grouped into UnknownOrigin.
+		{Name: UnknownOrigin, Totals: Totals{TotalCnt: 2, CoveredCnt: 1}, Resources: []string{"azurerm_unlinked", "azurerm_web_slot"}},
+		{Name: "web", Totals: Totals{TotalCnt: 2, CoveredCnt: 1}, Resources: []string{"azurerm_web_app"}},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Rollup = %+v, expected %+v", groups, expected)
+	}
+
+	groups, err = r.Rollup(GroupByNamespace, r.Origins(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	if expected := []string{"Microsoft.Storage", "Microsoft.Web", UnknownOrigin}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("namespaces = %v, expected %v", names, expected)
+	}
+
+	if _, err := r.Rollup("resource", origins); err == nil {
+		t.Errorf("Rollup by an unknown field returned no error")
+	}
+}
@@ -2,12 +2,14 @@ module github.com/ziyeqf/terraform-azurerm-provider-coverage
 
 go 1.20
 
-require github.com/go-openapi/jsonpointer v0.20.0
+require (
+	github.com/go-openapi/jsonpointer v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/go-openapi/swag v0.22.4 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/rogpeppe/go-internal v1.11.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -14,8 +14,11 @@ const (
 )
 
 type SchemaJSON struct {
-	Type string      `json:"type,omitempty"`
-	Elem interface{} `json:"elem,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Required bool        `json:"required,omitempty"`
+	Optional bool        `json:"optional,omitempty"`
+	Computed bool        `json:"computed,omitempty"`
+	Elem     interface{} `json:"elem,omitempty"`
 }
 
 func (b *SchemaJSON) UnmarshalJSON(body []byte) error {
@@ -25,6 +28,9 @@ func (b *SchemaJSON) UnmarshalJSON(body []byte) error {
 		return err
 	}
 	b.Type, _ = m["type"].(string)
+	b.Required, _ = m["required"].(bool)
+	b.Optional, _ = m["optional"].(bool)
+	b.Computed, _ = m["computed"].(bool)
 
 	if e, ok := m["elem"]; ok && e != nil {
 		b.Elem = decodeElem(e)
@@ -48,6 +54,9 @@ func SchemaFromMap(input map[string]interface{}) SchemaJSON {
 	if t, ok := input["type"]; ok {
 		result.Type = t.(string)
 	}
+	result.Required, _ = input["required"].(bool)
+	result.Optional, _ = input["optional"].(bool)
+	result.Computed, _ = input["computed"].(bool)
 
 	if t, ok := input["elem"]; ok {
 		result.Elem = decodeElem(t)
@@ -1,55 +1,121 @@
 package jsontree
 
 import (
+	"sort"
+
 	"github.com/go-openapi/jsonpointer"
 )
 
-//
-//type Node interface {
-//	PrimitiveNode | ArrayNode | ObjectNode
-//}
-//
-//type PrimitiveNode struct {
-//	Name string
-//}
-//
-//type ArrayNode struct {
-//	Name     string
-//	Children []interface{}
-//}
-//
-//type ObjectNode struct {
-//	Name     string
-//	Children map[string]interface{}
-//}
-//
-//func NewRootNode() ObjectNode {
-//	return ObjectNode{
-//		Children: make(map[string]interface{}),
-//	}
-//}
-
+// Node is a node of the tree parsed from json pointers, it has no context about the type of the node,
+// use Build to build a typed tree with the schema. The leaves inserted by Tree.Insert have nil Children.
 type Node struct {
 	Name     string
-	Children map[string]Node
+	Children map[string]*Node
 }
 
-func NewNode(name string) Node {
-	return Node{
+func NewNode(name string) *Node {
+	return &Node{
 		Name:     name,
-		Children: make(map[string]Node),
+		Children: make(map[string]*Node),
 	}
 }
 
-// ParseJsonPtr does not have context about the type of the created node, it will be created to ObjectNode.
-func ParseJsonPtr(root *Node, ptr jsonpointer.Pointer) (Node, error) {
-	tks := ptr.DecodedTokens()
-	cur := *root
+// Find returns the node of the tokens relative to n.
+func (n *Node) Find(tks ...string) (*Node, bool) {
+	cur := n
 	for _, tk := range tks {
-		if _, ok := cur.Children[tk]; !ok {
-			cur.Children[tk] = NewNode(tk)
+		next, ok := cur.Children[tk]
+		if !ok {
+			return nil, false
 		}
-		cur = cur.Children[tk]
+		cur = next
+	}
+	return cur, true
+}
+
+// ChildNames returns the names of the children sorted lexically.
+func (n *Node) ChildNames() []string {
+	result := make([]string, 0, len(n.Children))
+	for name := range n.Children {
+		result = append(result, name)
 	}
-	return *root, nil
+	sort.Strings(result)
+	return result
+}
+
+// Interner shares one string for the same tokens, so the names repeated across pointers and trees,
+// like indexes and common property names, are stored once.
+type Interner struct {
+	tokens map[string]string
+}
+
+func NewInterner() *Interner {
+	return &Interner{
+		tokens: make(map[string]string),
+	}
+}
+
+func (i *Interner) Intern(tk string) string {
+	if s, ok := i.tokens[tk]; ok {
+		return s
+	}
+	i.tokens[tk] = tk
+	return tk
+}
+
+// nodeChunkSize is the count of nodes allocated at once by Tree.Insert.
+const nodeChunkSize = 64
+
+// Tree is a tree of json pointers with interned tokens.
+type Tree struct {
+	Root     *Node
+	interner *Interner
+	// nodes are allocated but not yet inserted, the nodes are allocated in chunks to save allocations.
+	nodes []Node
+}
+
+// NewTree creates an empty tree, trees created with the same interner share the tokens.
+// A new interner is used if interner is nil.
+func NewTree(interner *Interner) *Tree {
+	if interner == nil {
+		interner = NewInterner()
+	}
+	return &Tree{
+		Root:     NewNode("/"),
+		interner: interner,
+	}
+}
+
+// Insert inserts the nodes of the pointer into the tree if not exist, and returns the node of the pointer.
+// The children map of a node is allocated when its first child is inserted, as most of the nodes are leaves.
+func (t *Tree) Insert(ptr jsonpointer.Pointer) *Node {
+	cur := t.Root
+	for _, tk := range ptr.DecodedTokens() {
+		next, ok := cur.Children[tk]
+		if !ok {
+			if cur.Children == nil {
+				cur.Children = make(map[string]*Node)
+			}
+			name := t.interner.Intern(tk)
+			next = t.newNode(name)
+			cur.Children[name] = next
+		}
+		cur = next
+	}
+	return cur
+}
+
+func (t *Tree) newNode(name string) *Node {
+	if len(t.nodes) == 0 {
+		t.nodes = make([]Node, nodeChunkSize)
+	}
+	n := &t.nodes[0]
+	t.nodes = t.nodes[1:]
+	n.Name = name
+	return n
+}
+
+// Find returns the node of the pointer.
+func (t *Tree) Find(ptr jsonpointer.Pointer) (*Node, bool) {
+	return t.Root.Find(ptr.DecodedTokens()...)
 }
@@ -0,0 +1,354 @@
+package jsontree
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+func newTestTree(t *testing.T, interner *Interner, ptrs ...string) *Tree {
+	t.Helper()
+	tree := NewTree(interner)
+	for _, p := range ptrs {
+		ptr, err := jsonpointer.New(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Insert(ptr)
+	}
+	return tree
+}
+
+func TestTreeInsert(t *testing.T) {
+	tree := newTestTree(t, nil, "/name", "/site_config/0/always_on", "/site_config/1/always_on", "/tags/a~1b", "/tags/c~0d")
+
+	cases := []struct {
+		tks      []string
+		children []string
+	}{
+		{nil, []string{"name", "site_config", "tags"}},
+		{[]string{"name"}, []string{}},
+		{[]string{"site_config"}, []string{"0", "1"}},
+		{[]string{"site_config", "0"}, []string{"always_on"}},
+		{[]string{"site_config", "1", "always_on"}, []string{}},
+		// the tokens are stored decoded.
+		{[]string{"tags"}, []string{"a/b", "c~d"}},
+	}
+	for _, c := range cases {
+		n, ok := tree.Root.Find(c.tks...)
+		if !ok {
+			t.Errorf("%v not found", c.tks)
+			continue
+		}
+		if actual := n.ChildNames(); !reflect.DeepEqual(actual, c.children) {
+			t.Errorf("children of %v = %v, expected %v", c.tks, actual, c.children)
+		}
+		if len(c.tks) > 0 && n.Name != c.tks[len(c.tks)-1] {
+			t.Errorf("name of %v = %q", c.tks, n.Name)
+		}
+	}
+
+	// inserting an existing pointer returns the existing node and adds nothing.
+	ptr, _ := jsonpointer.New("/site_config/0")
+	expected, _ := tree.Find(ptr)
+	if actual := tree.Insert(ptr); actual != expected {
+		t.Errorf("Insert of an existing pointer returned a new node")
+	}
+	if names := expected.ChildNames(); !reflect.DeepEqual(names, []string{"always_on"}) {
+		t.Errorf("children of /site_config/0 = %v after inserting it again", names)
+	}
+
+	// the empty pointer is the root.
+	root, _ := jsonpointer.New("")
+	if n := tree.Insert(root); n != tree.Root {
+		t.Errorf("Insert of the empty pointer is not the root")
+	}
+	if tree.Root.Name != "/" {
+		t.Errorf("name of the root = %q", tree.Root.Name)
+	}
+}
+
+func TestNodeFind(t *testing.T) {
+	tree := newTestTree(t, nil, "/site_config/0/always_on")
+	cases := []struct {
+		ptr   string
+		found bool
+	}{
+		{"", true},
+		{"/site_config", true},
+		{"/site_config/0/always_on", true},
+		{"/site_config/1", false},
+		{"/site_config/0/always_on/0", false},
+		{"/name", false},
+	}
+	for _, c := range cases {
+		ptr, _ := jsonpointer.New(c.ptr)
+		n, ok := tree.Find(ptr)
+		if ok != c.found {
+			t.Errorf("Find(%q) found = %t, expected %t", c.ptr, ok, c.found)
+			continue
+		}
+		if !ok && n != nil {
+			t.Errorf("Find(%q) returned a node which is not found", c.ptr)
+		}
+	}
+
+	// Find is relative to the node.
+	sc, _ := tree.Root.Find("site_config")
+	if n, ok := sc.Find("0", "always_on"); !ok || n.Name != "always_on" {
+		t.Errorf("site_config.Find(0, always_on) = %v, %t", n, ok)
+	}
+	if n, ok := sc.Find(); !ok || n != sc {
+		t.Errorf("Find without tokens is not the node itself")
+	}
+}
+
+func TestNodeChildNames(t *testing.T) {
+	n := NewNode("/")
+	if names := n.ChildNames(); len(names) != 0 {
+		t.Errorf("ChildNames of an empty node = %v", names)
+	}
+	// the names are sorted lexically, indexes are not sorted numerically.
+	tree := newTestTree(t, nil, "/b", "/a", "/10", "/2", "/1")
+	expected := []string{"1", "10", "2", "a", "b"}
+	if names := tree.Root.ChildNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("ChildNames = %v, expected %v", names, expected)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	i := NewInterner()
+	// the tokens are copied, so they are different strings of the same content.
+	a := i.Intern(string([]byte("name")))
+	b := i.Intern(string([]byte("name")))
+	if a != "name" || b != "name" {
+		t.Fatalf("Intern changed the tokens: %q, %q", a, b)
+	}
+	if unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("the same tokens are not shared")
+	}
+	if c := i.Intern("location"); c != "location" {
+		t.Errorf("Intern(location) = %q", c)
+	}
+
+	// the trees created with the same interner share the tokens.
+	t1 := newTestTree(t, i, string([]byte("/xxx")))
+	t2 := newTestTree(t, i, string([]byte("/xxx")))
+	n1, _ := t1.Root.Find("xxx")
+	n2, _ := t2.Root.Find("xxx")
+	if unsafe.StringData(n1.Name) != unsafe.StringData(n2.Name) {
+		t.Errorf("the trees of the same interner don't share the tokens")
+	}
+}
+
+// legacyNode is the former tree which stores children by value, it's kept to compare the allocations.
+type legacyNode struct {
+	Name     string
+	Children map[string]legacyNode
+}
+
+func newLegacyNode(name string) legacyNode {
+	return legacyNode{
+		Name:     name,
+		Children: make(map[string]legacyNode),
+	}
+}
+
+func legacyParseJsonPtr(root *legacyNode, ptr jsonpointer.Pointer) (legacyNode, error) {
+	tks := ptr.DecodedTokens()
+	cur := *root
+	for _, tk := range tks {
+		if _, ok := cur.Children[tk]; !ok {
+			cur.Children[tk] = newLegacyNode(tk)
+		}
+		cur = cur.Children[tk]
+	}
+	return *root, nil
+}
+
+func legacyFind(root *legacyNode, tks []string) (*legacyNode, bool) {
+	cur := root
+	for _, tk := range tks {
+		if _, ok := cur.Children[tk]; !ok {
+			return nil, false
+		}
+		n, _ := cur.Children[tk]
+		cur = &n
+	}
+	return cur, true
+}
+
+var (
+	fullProviderOnce     sync.Once
+	fullProviderPointers map[string][]jsonpointer.Pointer
+	fullProviderErr      error
+)
+
+// loadFullProvider generates a coverage map of all the properties of all the resources in testdata/schema.json,
+// blocks are expanded with two indexes and maps with a key.
+func loadFullProvider(b *testing.B) map[string][]jsonpointer.Pointer {
+	fullProviderOnce.Do(func() {
+		schema, err := jsonhelper.ParseSchema("../testdata/schema.json")
+		if err != nil {
+			fullProviderErr = err
+			return
+		}
+		fullProviderPointers = make(map[string][]jsonpointer.Pointer)
+		for resType, res := range schema.ProviderSchema.ResourcesMap {
+			for _, p := range schemaPointers(res.Schema, "") {
+				ptr, err := jsonpointer.New(p)
+				if err != nil {
+					fullProviderErr = err
+					return
+				}
+				fullProviderPointers[resType] = append(fullProviderPointers[resType], ptr)
+			}
+		}
+	})
+	if fullProviderErr != nil {
+		b.Fatal(fullProviderErr)
+	}
+	return fullProviderPointers
+}
+
+func schemaPointers(schema map[string]jsonhelper.SchemaJSON, prefix string) []string {
+	result := make([]string, 0)
+	for name, sch := range schema {
+		ptr := prefix + "/" + name
+		switch elem := sch.Elem.(type) {
+		case jsonhelper.ResourceJSON:
+			for _, idx := range []string{"0", "1"} {
+				result = append(result, schemaPointers(elem.Schema, ptr+"/"+idx)...)
+			}
+		case string:
+			if sch.Type == jsonhelper.SchemaTypeMap {
+				result = append(result, ptr+"/KEY")
+			} else {
+				result = append(result, ptr+"/0")
+			}
+		default:
+			result = append(result, ptr)
+		}
+	}
+	return result
+}
+
+func countPointers(ptrs map[string][]jsonpointer.Pointer) int {
+	cnt := 0
+	for _, p := range ptrs {
+		cnt += len(p)
+	}
+	return cnt
+}
+
+func BenchmarkTreeInsert(b *testing.B) {
+	ptrs := loadFullProvider(b)
+	b.ReportMetric(float64(countPointers(ptrs)), "pointers/op")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interner := NewInterner()
+		for _, res := range ptrs {
+			tree := NewTree(interner)
+			for _, ptr := range res {
+				tree.Insert(ptr)
+			}
+		}
+	}
+}
+
+func BenchmarkLegacyParseJsonPtr(b *testing.B) {
+	ptrs := loadFullProvider(b)
+	b.ReportMetric(float64(countPointers(ptrs)), "pointers/op")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, res := range ptrs {
+			root := newLegacyNode("/")
+			for _, ptr := range res {
+				if _, err := legacyParseJsonPtr(&root, ptr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkTreeFind(b *testing.B) {
+	ptrs := loadFullProvider(b)
+	trees := make(map[string]*Tree)
+	interner := NewInterner()
+	for resType, res := range ptrs {
+		trees[resType] = NewTree(interner)
+		for _, ptr := range res {
+			trees[resType].Insert(ptr)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for resType, res := range ptrs {
+			for _, ptr := range res {
+				if _, ok := trees[resType].Root.Find(ptr.DecodedTokens()...); !ok {
+					b.Fatalf("%s not found", ptr.String())
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkLegacyFind(b *testing.B) {
+	ptrs := loadFullProvider(b)
+	roots := make(map[string]*legacyNode)
+	for resType, res := range ptrs {
+		root := newLegacyNode("/")
+		for _, ptr := range res {
+			if _, err := legacyParseJsonPtr(&root, ptr); err != nil {
+				b.Fatal(err)
+			}
+		}
+		roots[resType] = &root
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for resType, res := range ptrs {
+			for _, ptr := range res {
+				if _, ok := legacyFind(roots[resType], ptr.DecodedTokens()); !ok {
+					b.Fatalf("%s not found", ptr.String())
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	ptrs := loadFullProvider(b)
+	schema, err := jsonhelper.ParseSchema("../testdata/schema.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	trees := make(map[string]*Tree)
+	interner := NewInterner()
+	for resType, res := range ptrs {
+		trees[resType] = NewTree(interner)
+		for _, ptr := range res {
+			trees[resType].Insert(ptr)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for resType, tree := range trees {
+			root := Build(tree.Root, schema.ProviderSchema.ResourcesMap[resType].Schema)
+			if unknown := UnknownPointers(root); len(unknown) > 0 {
+				b.Fatalf("unexpected unknown pointers of %s: %s", resType, strings.Join(unknown, ", "))
+			}
+		}
+	}
+}
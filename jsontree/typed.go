@@ -0,0 +1,276 @@
+package jsontree
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+type Kind string
+
+const (
+	KindObject    Kind = "object"
+	KindArray     Kind = "array"
+	KindMap       Kind = "map"
+	KindPrimitive Kind = "primitive"
+)
+
+// TypedNode is a node of the tree built with the schema, so the indexes of lists and sets, the keys of maps and the fields of blocks are told apart.
+type TypedNode interface {
+	Kind() Kind
+	// Name is the last token of the pointer, it's a field name for the fields of objects, an index for array items and a key for map entries.
+	Name() string
+	Pointer() string
+	// Children returns the child nodes sorted by name, indexes are sorted numerically.
+	Children() []TypedNode
+}
+
+type typedBase struct {
+	name string
+	ptr  string
+}
+
+func (b typedBase) Name() string {
+	return b.name
+}
+
+func (b typedBase) Pointer() string {
+	return b.ptr
+}
+
+// ObjectNode is a resource or a block.
+type ObjectNode struct {
+	typedBase
+	Fields map[string]TypedNode
+	// Unknown are the pointers under the object which are not found in the schema.
+	Unknown []string
+}
+
+func (n *ObjectNode) Kind() Kind {
+	return KindObject
+}
+
+func (n *ObjectNode) Children() []TypedNode {
+	return sortedNodes(n.Fields)
+}
+
+// ArrayNode is a list or a set, the items are either objects or primitives.
+type ArrayNode struct {
+	typedBase
+	Items map[string]TypedNode
+}
+
+func (n *ArrayNode) Kind() Kind {
+	return KindArray
+}
+
+func (n *ArrayNode) Children() []TypedNode {
+	return sortedNodes(n.Items)
+}
+
+// MapNode is a map, the entries are either objects or primitives.
+type MapNode struct {
+	typedBase
+	Entries map[string]TypedNode
+}
+
+func (n *MapNode) Kind() Kind {
+	return KindMap
+}
+
+func (n *MapNode) Children() []TypedNode {
+	return sortedNodes(n.Entries)
+}
+
+type PrimitiveNode struct {
+	typedBase
+}
+
+func (n *PrimitiveNode) Kind() Kind {
+	return KindPrimitive
+}
+
+func (n *PrimitiveNode) Children() []TypedNode {
+	return nil
+}
+
+// Build builds the typed tree of a resource from the parsed coverage tree with the schema of the resource.
+// The nodes not found in the schema are recorded in `Unknown` of the closest object.
+func Build(root *Node, schema map[string]jsonhelper.SchemaJSON) *ObjectNode {
+	return buildObject(root, "", "", schema)
+}
+
+func buildObject(n *Node, name, ptr string, schema map[string]jsonhelper.SchemaJSON) *ObjectNode {
+	obj := &ObjectNode{
+		typedBase: typedBase{name: name, ptr: ptr},
+		Fields:    make(map[string]TypedNode),
+	}
+	for _, childName := range n.ChildNames() {
+		child := n.Children[childName]
+		childPtr := ptr + "/" + jsonpointer.Escape(childName)
+		sch, ok := schema[childName]
+		if !ok {
+			obj.Unknown = append(obj.Unknown, pointers(child, childPtr)...)
+			continue
+		}
+		obj.Fields[childName] = obj.buildField(child, childName, childPtr, sch)
+	}
+	return obj
+}
+
+func (obj *ObjectNode) buildField(n *Node, name, ptr string, sch jsonhelper.SchemaJSON) TypedNode {
+	base := typedBase{name: name, ptr: ptr}
+
+	var items map[string]TypedNode
+	var result TypedNode
+	switch sch.Type {
+	case jsonhelper.SchemaTypeList, jsonhelper.SchemaTypeSet:
+		arr := &ArrayNode{typedBase: base, Items: make(map[string]TypedNode)}
+		items, result = arr.Items, arr
+	case jsonhelper.SchemaTypeMap:
+		m := &MapNode{typedBase: base, Entries: make(map[string]TypedNode)}
+		items, result = m.Entries, m
+	default:
+		for _, childName := range n.ChildNames() {
+			obj.Unknown = append(obj.Unknown, pointers(n.Children[childName], ptr+"/"+jsonpointer.Escape(childName))...)
+		}
+		return &PrimitiveNode{typedBase: base}
+	}
+
+	block, isBlock := sch.Elem.(jsonhelper.ResourceJSON)
+	for _, childName := range n.ChildNames() {
+		child := n.Children[childName]
+		childPtr := ptr + "/" + jsonpointer.Escape(childName)
+		if isBlock {
+			items[childName] = buildObject(child, childName, childPtr, block.Schema)
+			continue
+		}
+		items[childName] = &PrimitiveNode{typedBase: typedBase{name: childName, ptr: childPtr}}
+		for _, grandChildName := range child.ChildNames() {
+			obj.Unknown = append(obj.Unknown, pointers(child.Children[grandChildName], childPtr+"/"+jsonpointer.Escape(grandChildName))...)
+		}
+	}
+	return result
+}
+
+// Walk calls fn for n and all the nodes under it in depth-first order, the children are visited in the order of Children.
+func Walk(n TypedNode, fn func(TypedNode) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children() {
+		if err := Walk(child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup finds the node of the json pointer relative to root.
+func Lookup(root TypedNode, ptrStr string) (TypedNode, bool) {
+	ptr, err := jsonpointer.New(ptrStr)
+	if err != nil {
+		return nil, false
+	}
+
+	cur := root
+	for _, tk := range ptr.DecodedTokens() {
+		var children map[string]TypedNode
+		switch n := cur.(type) {
+		case *ObjectNode:
+			children = n.Fields
+		case *ArrayNode:
+			children = n.Items
+		case *MapNode:
+			children = n.Entries
+		default:
+			return nil, false
+		}
+		next, ok := children[tk]
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+// UnknownPointers returns the pointers not found in the schema of all the objects under n, sorted lexically.
+func UnknownPointers(n TypedNode) []string {
+	result := make([]string, 0)
+	_ = Walk(n, func(n TypedNode) error {
+		if obj, ok := n.(*ObjectNode); ok {
+			result = append(result, obj.Unknown...)
+		}
+		return nil
+	})
+	sort.Strings(result)
+	return result
+}
+
+// Fprint pretty-prints the tree of n with the kind of each node.
+func Fprint(w io.Writer, n TypedNode) error {
+	b := &strings.Builder{}
+	var print func(n TypedNode, depth int)
+	print = func(n TypedNode, depth int) {
+		name := n.Name()
+		if depth == 0 && name == "" {
+			name = "/"
+		}
+		fmt.Fprintf(b, "%s%s (%s)\n", strings.Repeat("  ", depth), name, n.Kind())
+		for _, child := range n.Children() {
+			print(child, depth+1)
+		}
+	}
+	print(n, 0)
+
+	if unknown := UnknownPointers(n); len(unknown) > 0 {
+		b.WriteString("unknown:\n")
+		for _, ptr := range unknown {
+			fmt.Fprintf(b, "  %s\n", ptr)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// pointers returns the pointers of n and all the nodes under it.
+func pointers(n *Node, ptr string) []string {
+	result := []string{ptr}
+	for _, name := range n.ChildNames() {
+		result = append(result, pointers(n.Children[name], ptr+"/"+jsonpointer.Escape(name))...)
+	}
+	return result
+}
+
+func sortedNodes(nodes map[string]TypedNode) []TypedNode {
+	result := make([]TypedNode, 0, len(nodes))
+	for _, n := range nodes {
+		result = append(result, n)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lessToken(result[i].Name(), result[j].Name())
+	})
+	return result
+}
+
+// lessToken sorts indexes numerically and the others lexically, indexes go first.
+func lessToken(a, b string) bool {
+	ai, aErr := strconv.Atoi(a)
+	bi, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return ai < bi
+	case aErr == nil:
+		return true
+	case bErr == nil:
+		return false
+	}
+	return a < b
+}
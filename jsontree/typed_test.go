@@ -0,0 +1,214 @@
+package jsontree
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+func testSchema() map[string]jsonhelper.SchemaJSON {
+	return map[string]jsonhelper.SchemaJSON{
+		"name":  {Type: "TypeString"},
+		"tags":  {Type: jsonhelper.SchemaTypeMap, Elem: "TypeString"},
+		"zones": {Type: jsonhelper.SchemaTypeList, Elem: "TypeString"},
+		"site_config": {Type: jsonhelper.SchemaTypeList, Elem: jsonhelper.ResourceJSON{Schema: map[string]jsonhelper.SchemaJSON{
+			"always_on": {Type: "TypeBool"},
+			"ip_restriction": {Type: jsonhelper.SchemaTypeSet, Elem: jsonhelper.ResourceJSON{Schema: map[string]jsonhelper.SchemaJSON{
+				"priority": {Type: "TypeInt"},
+			}}},
+		}}},
+	}
+}
+
+func testTyped(t *testing.T) *ObjectNode {
+	t.Helper()
+	tree := newTestTree(t, nil,
+		"/name",
+		"/tags/KEY",
+		"/tags/0",
+		"/zones/0",
+		"/zones/1",
+		"/site_config/0/always_on",
+		"/site_config/0/ip_restriction/10/priority",
+		"/site_config/0/ip_restriction/2/priority",
+		// a primitive where the schema expects a block or a collection.
+		"/name/0",
+		"/zones/0/name",
+		// fields not in the schema, the whole subtree is unknown.
+		"/site_config/0/not_a_property/1",
+		"/a~1b",
+	)
+	return Build(tree.Root, testSchema())
+}
+
+func TestBuild(t *testing.T) {
+	root := testTyped(t)
+	cases := []struct {
+		ptr  string
+		kind Kind
+		name string
+	}{
+		{"", KindObject, ""},
+		{"/name", KindPrimitive, "name"},
+		// the keys of a map are entries, even if a key looks like an index.
+		{"/tags", KindMap, "tags"},
+		{"/tags/KEY", KindPrimitive, "KEY"},
+		{"/tags/0", KindPrimitive, "0"},
+		{"/zones", KindArray, "zones"},
+		{"/zones/1", KindPrimitive, "1"},
+		// the indexes of a block are objects with the fields of the block.
+		{"/site_config", KindArray, "site_config"},
+		{"/site_config/0", KindObject, "0"},
+		{"/site_config/0/always_on", KindPrimitive, "always_on"},
+		{"/site_config/0/ip_restriction", KindArray, "ip_restriction"},
+		{"/site_config/0/ip_restriction/10", KindObject, "10"},
+		{"/site_config/0/ip_restriction/10/priority", KindPrimitive, "priority"},
+	}
+	for _, c := range cases {
+		n, ok := Lookup(root, c.ptr)
+		if !ok {
+			t.Errorf("%q not found", c.ptr)
+			continue
+		}
+		if n.Kind() != c.kind || n.Name() != c.name || n.Pointer() != c.ptr {
+			t.Errorf("%q = (%s, %q, %q), expected (%s, %q, %q)", c.ptr, n.Kind(), n.Name(), n.Pointer(), c.kind, c.name, c.ptr)
+		}
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	root := testTyped(t)
+	for _, ptr := range []string{
+		"/location",
+		"/site_config/1",
+		// the children of primitives and the unknown fields are not in the typed tree.
+		"/name/0",
+		"/zones/0/name",
+		"/site_config/0/not_a_property",
+		"/a~1b",
+		// not a json pointer.
+		"name",
+	} {
+		if n, ok := Lookup(root, ptr); ok {
+			t.Errorf("%q is found as %s", ptr, n.Kind())
+		}
+	}
+}
+
+func TestUnknownPointers(t *testing.T) {
+	root := testTyped(t)
+	cases := []struct {
+		ptr      string
+		expected []string
+	}{
+		{"", []string{
+			"/a~1b",
+			"/name/0",
+			"/site_config/0/not_a_property",
+			"/site_config/0/not_a_property/1",
+			"/zones/0/name",
+		}},
+		// only the unknown pointers under the node are returned.
+		{"/site_config", []string{
+			"/site_config/0/not_a_property",
+			"/site_config/0/not_a_property/1",
+		}},
+		{"/tags", []string{}},
+	}
+	for _, c := range cases {
+		n, ok := Lookup(root, c.ptr)
+		if !ok {
+			t.Fatalf("%q not found", c.ptr)
+		}
+		if actual := UnknownPointers(n); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("UnknownPointers(%q) = %v, expected %v", c.ptr, actual, c.expected)
+		}
+	}
+
+	// the unknown pointers are recorded in the closest object.
+	sc, _ := Lookup(root, "/site_config/0")
+	if unknown := sc.(*ObjectNode).Unknown; len(unknown) != 2 {
+		t.Errorf("unknown of /site_config/0 = %v", unknown)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := testTyped(t)
+	sc, _ := Lookup(root, "/site_config")
+
+	visited := make([]string, 0)
+	if err := Walk(sc, func(n TypedNode) error {
+		visited = append(visited, n.Pointer())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// depth-first, indexes are sorted numerically.
+	expected := []string{
+		"/site_config",
+		"/site_config/0",
+		"/site_config/0/always_on",
+		"/site_config/0/ip_restriction",
+		"/site_config/0/ip_restriction/2",
+		"/site_config/0/ip_restriction/2/priority",
+		"/site_config/0/ip_restriction/10",
+		"/site_config/0/ip_restriction/10/priority",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("visited %v, expected %v", visited, expected)
+	}
+
+	// the walk stops at the first error.
+	stop := errors.New("stop")
+	cnt := 0
+	err := Walk(root, func(n TypedNode) error {
+		cnt++
+		if n.Kind() == KindArray {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Walk returned %v, expected %v", err, stop)
+	}
+	// "/" and "/name" go before the first array "/site_config".
+	if cnt != 3 {
+		t.Errorf("visited %d nodes before the error, expected 3", cnt)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	b := &strings.Builder{}
+	if err := Fprint(b, testTyped(t)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `/ (object)
+  name (primitive)
+  site_config (array)
+    0 (object)
+      always_on (primitive)
+      ip_restriction (array)
+        2 (object)
+          priority (primitive)
+        10 (object)
+          priority (primitive)
+  tags (map)
+    0 (primitive)
+    KEY (primitive)
+  zones (array)
+    0 (primitive)
+    1 (primitive)
+unknown:
+  /a~1b
+  /name/0
+  /site_config/0/not_a_property
+  /site_config/0/not_a_property/1
+  /zones/0/name
+`
+	if actual := b.String(); actual != expected {
+		t.Errorf("Fprint:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
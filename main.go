@@ -1,149 +1,223 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"sort"
 	"strings"
 
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/config"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
 	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
 	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
 )
 
-func main() {
-	coverageFile := flag.String("input", "", "the input file of schema")
-	schemaFile := flag.String("schema", "", "the schema dump of azurerm provider")
-	ignoreSchemas := flag.String("ignore-schema", "", "the schema to ignore of azurerm provider")
-	ignoreUncoveredResources := flag.Bool("ignore-uncovered-resources", false, "ignore uncovered resources")
-	diagnosticsOutput := flag.Bool("diagnostics-output", false, "output diagnostics information")
-	portalOutput := flag.Bool("portal-output", false, "output to fit portal format")
-	flag.Parse()
-
-	coverageMap, err := jsonhelper.ParseCoverageFile(*coverageFile)
-	if err != nil {
-		exitOnError(err)
-	}
+type command struct {
+	name    string
+	summary string
+	// usage is the arguments after the command name.
+	usage       string
+	description string
+	examples    []string
+	// setup registers the flags of the command, and returns the function to run the command with the positional arguments.
+	setup func(fs *flag.FlagSet) func(args []string)
+}
 
-	schema, err := jsonhelper.ParseSchema(*schemaFile)
-	if err != nil {
-		exitOnError(err)
+var commands []command
+
+func init() {
+	commands = []command{
+		reportCommand,
+		configCommand,
+		diffCommand,
+		validateCommand,
+		inspectCommand,
+		explainCommand,
+		serveCommand,
 	}
+}
 
-	ignoreSchemaList := make([]string, 0)
-	if ignoreSchemas != nil && *ignoreSchemas != "" {
-		ignoreSchemaList = append(ignoreSchemaList, strings.Split(*ignoreSchemas, ",")...)
+func main() {
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			runHelp(args[1:])
+			return
+		}
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				cmd.execute(args[1:])
+				return
+			}
+		}
 	}
 
-	r, err := runner.NwRunner(runner.Opts{
-		Resources:                schema.ProviderSchema.ResourcesMap,
-		CoverageMap:              coverageMap,
-		IgnoreSchemas:            ignoreSchemaList,
-		IgnoreUncoveredResources: *ignoreUncoveredResources,
-	})
-	if err != nil {
-		exitOnError(err)
+	// the flags without a command are taken as the report command for compatibility.
+	reportCommand.execute(args)
+}
+
+func (cmd command) execute(args []string) {
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		cmd.printUsage(fs)
 	}
+	run := cmd.setup(fs)
+	_ = fs.Parse(args)
+	run(fs.Args())
+}
 
-	detail, scmCnt, covCnt, err := r.Run()
-	if err != nil {
-		exitOnError(err)
-	}
-
-	var output interface{}
-	if !*portalOutput {
-		o := make(map[string]map[string][]string)
-		for k, resource := range detail {
-			o[k] = make(map[string][]string)
-			o[k]["covered_properties"] = make([]string, 0)
-			o[k]["uncovered_properties"] = make([]string, 0)
-			for name, prop := range resource {
-				if prop != nil {
-					o[k]["covered_properties"] = append(o[k]["covered_properties"], name)
-				} else {
-					o[k]["uncovered_properties"] = append(o[k]["uncovered_properties"], name)
-				}
-			}
-		}
-		output = o
-		if *diagnosticsOutput {
-			diagOutput(covCnt, scmCnt, ignoreUncoveredResources, coverageMap)
+func (cmd command) printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: %s %s %s\n\n%s\n", os.Args[0], cmd.name, cmd.usage, cmd.description)
+	if len(cmd.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, e := range cmd.examples {
+			fmt.Fprintf(w, "  %s %s\n", os.Args[0], e)
 		}
-	} else {
-		resources := make([]jsonhelper.ResourceOutput, 0)
-		for k, v := range detail {
-			rt, err := jsonhelper.GenResourceOutput(k, v)
-			if err != nil {
-				exitOnError(err)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+}
+
+func runHelp(args []string) {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+				fs.SetOutput(os.Stdout)
+				cmd.setup(fs)
+				cmd.printUsage(fs)
+				return
 			}
-			resources = append(resources, rt)
 		}
-		sort.Slice(resources, func(i, j int) bool {
-			return resources[i].Name < resources[j].Name
-		})
+		exitOnError(fmt.Errorf("unknown command %q", args[0]))
+	}
 
-		output = map[string]interface{}{
-			"resources": resources,
-		}
+	fmt.Printf("Usage: %s <command> [flags] [args]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Printf("\nThe flags without a command run the report command.\nRun `%s help <command>` for the flags and examples of a command.\n", os.Args[0])
+}
 
-		if *diagnosticsOutput {
-			output.(map[string]interface{})["diagnostics"] = jsonhelper.GenPortalDiagnosticOutput(covCnt, scmCnt, ignoreUncoveredResources, coverageMap)
-		}
+// inputFlags are the flags shared by the commands reading the coverage and schema files.
+type inputFlags struct {
+	fs            *flag.FlagSet
+	configFile    *string
+	coverageFile  *string
+	schemaFile    *string
+	ignoreSchemas *string
+}
+
+func addInputFlags(fs *flag.FlagSet) inputFlags {
+	return inputFlags{
+		fs:            fs,
+		configFile:    fs.String("config", "", "the configuration file in JSON or YAML, the flags set explicitly override it"),
+		coverageFile:  fs.String("input", "", "the input file of schema"),
+		schemaFile:    fs.String("schema", "", "the schema dump of azurerm provider"),
+		ignoreSchemas: fs.String("ignore-schema", "", "the schema to ignore of azurerm provider"),
 	}
+}
 
-	b, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		exitOnError(err)
+// config returns the defaults overridden by the configuration file and then by the flags set explicitly.
+func (f inputFlags) config() (config.Config, error) {
+	cfg := defaultConfig()
+	if *f.configFile != "" {
+		if err := config.Load(*f.configFile, &cfg); err != nil {
+			return cfg, err
+		}
 	}
-	fmt.Println(string(b))
 
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "input":
+			cfg.Input = *f.coverageFile
+		case "schema":
+			cfg.Schema = *f.schemaFile
+		case "ignore-schema":
+			cfg.IgnoreSchemas = splitList(*f.ignoreSchemas)
+		}
+	})
+	return cfg, nil
 }
 
-func exitOnError(err error) {
-	log.Println(err.Error())
-	os.Exit(1)
+func defaultConfig() config.Config {
+	return config.Config{
+		IgnoreSchemas: make([]string, 0),
+		Badge: config.Badge{
+			Scope:      report.BadgeScopeTotal,
+			Thresholds: report.DefaultBadgeThresholds,
+		},
+		Table: config.Table{
+			Sort:  report.TableSortPercent,
+			Color: report.ColorAuto,
+		},
+		Outputs: []config.Output{{Format: "json"}},
+	}
 }
 
-func diagOutput(covCnt, scmCnt map[string]int, ignoreUncoveredResources *bool, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage) {
-	fmt.Println("----------------------------------------")
-	totalScm := 0
-	totalCov := 0
-	// coverage and schema might have difference
+func loadInputs(cfg config.Config) (map[string]map[string][]jsonhelper.PropertyCoverage, map[string]jsonhelper.ResourceJSON, error) {
+	coverageMap, err := jsonhelper.ParseCoverageFile(cfg.Input)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for _, v := range covCnt {
-		totalCov += v
+	schema, err := jsonhelper.ParseSchema(cfg.Schema)
+	if err != nil {
+		return nil, nil, err
 	}
+	return coverageMap, schema.ProviderSchema.ResourcesMap, nil
+}
 
-	for _, v := range scmCnt {
-		totalScm += v
+// splitList splits a comma-separated flag value, an empty value is an empty list.
+func splitList(s string) []string {
+	result := make([]string, 0)
+	if s != "" {
+		result = append(result, strings.Split(s, ",")...)
 	}
+	return result
+}
 
-	resultCnt := scmCnt
-	if *ignoreUncoveredResources {
-		resultCnt = covCnt
+func runReport(resources map[string]jsonhelper.ResourceJSON, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage, ignoreSchemas []string, ignoreUncoveredResources bool) (*coverage.Result, error) {
+	r, err := runner.NwRunner(runner.Opts{
+		Resources:                resources,
+		CoverageMap:              coverageMap,
+		IgnoreSchemas:            ignoreSchemas,
+		IgnoreUncoveredResources: ignoreUncoveredResources,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	issueRes := make([]string, 0)
-	fmt.Println("resource coverage detail:")
-	for k := range resultCnt {
-		percent := float64(covCnt[k]) / float64(scmCnt[k]) * 100
-		if covCnt[k] != len(coverageMap[k]) {
-			issueRes = append(issueRes, fmt.Sprintf("%s: statics count: %d, coverage count: %d", k, covCnt[k], len(coverageMap[k])))
-		}
-		fmt.Println(fmt.Sprintf("resource: %s, schema cnt: %d, coverage cnt: %d, percent: %.2f%%", k, scmCnt[k], covCnt[k], percent))
+	return r.Run()
+}
+
+// writeOutput writes the rendered output into the file of path, or stdout if path is empty or `-`.
+func writeOutput(path string, renderFunc func(w io.Writer) error) error {
+	if isStdout(path) {
+		return renderFunc(os.Stdout)
 	}
-	fmt.Println("----------------------------------------")
 
-	if len(issueRes) > 0 {
-		fmt.Println("coverage issue resources:")
-		for _, res := range issueRes {
-			fmt.Println(res)
-		}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create output file: %v", err)
+	}
+	if err := renderFunc(f); err != nil {
+		f.Close()
+		return err
 	}
-	fmt.Println("----------------------------------------")
-	fmt.Println(fmt.Sprintf("total resources: %d", len(scmCnt)))
-	fmt.Println(fmt.Sprintf("total count schema: %d, coverage: %d, percent: %.2f%%", totalScm, totalCov, float64(totalCov)/float64(totalScm)*100))
-	fmt.Println("----------------------------------------")
+	return f.Close()
+}
+
+func isStdout(path string) bool {
+	return path == "" || path == "-"
+}
+
+func exitOnError(err error) {
+	log.Println(err.Error())
+	os.Exit(1)
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+)
+
+var update = flag.Bool("update", false, "update the golden files under testdata/golden")
+
+// the outputs are rendered several times, as the iteration order of go maps differs between iterations.
+const renderTimes = 5
+
+func TestGoldenOutputs(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formats := []string{"json", "portal", "table", "service", "namespace-json", "api-versions", "api-versions-json", "markdown", "html", "csv", "tsv-summary", "junit", "cobertura", "lcov", "prometheus"}
+	cases := []struct {
+		name          string
+		input         string
+		ignoreSchemas []string
+	}{
+		{
+			name:  "coverage",
+			input: "testdata/coverage.json",
+		},
+		{
+			name:          "coverage_nested",
+			input:         "testdata/coverage_nested.json",
+			ignoreSchemas: []string{"location"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			coverageMap, err := jsonhelper.ParseCoverageFile(c.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < renderTimes; i++ {
+				in, err := runReport(schema.ProviderSchema.ResourcesMap, coverageMap, c.ignoreSchemas, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				renderOpts := report.Options{
+					Diagnostics: true,
+					Table: report.TableOptions{
+						Color: report.ColorNever,
+					},
+				}
+
+				for _, format := range formats {
+					renderer, err := report.NewRenderer(format, renderOpts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					buf := &bytes.Buffer{}
+					if err := renderer.Render(buf, in); err != nil {
+						t.Fatalf("render %s: %v", format, err)
+					}
+					checkGolden(t, c.name+"."+format, buf.Bytes(), i == 0)
+				}
+
+				buf := &bytes.Buffer{}
+				diagOutput(buf, in)
+				checkGolden(t, c.name+".diagnostics", buf.Bytes(), i == 0)
+			}
+		})
+	}
+}
+
+func TestGoldenInspect(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverageMap, err := jsonhelper.ParseCoverageFile("testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resType := "azurerm_linux_web_app"
+	for _, c := range []struct {
+		name          string
+		uncoveredOnly bool
+	}{
+		{name: "inspect"},
+		{name: "inspect_uncovered", uncoveredOnly: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := runInspect(buf, schema.ProviderSchema.ResourcesMap, resType, coverageMap, []string{"location"}, report.InspectOptions{
+				UncoveredOnly: c.uncoveredOnly,
+				Color:         report.ColorNever,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "coverage_nested."+c.name, buf.Bytes(), true)
+		})
+	}
+}
+
+func TestGoldenExplain(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverageMap, err := jsonhelper.ParseCoverageFile("testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		pointer string
+	}{
+		{name: "display_pointer", pointer: "/site_config/0/ip_restriction/0/priority"},
+		{name: "index_pointer", pointer: "/site_config/0/ip_restriction/1/priority"},
+		{name: "schema_path", pointer: "site_config/always_on"},
+		{name: "ignored", pointer: "/location"},
+		{name: "unknown_pointer", pointer: "/site_config/0/not_a_property"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			// the error is locked down in the golden file as well.
+			if err := runExplain(buf, schema.ProviderSchema.ResourcesMap, coverageMap, []string{"location"}, "azurerm_linux_web_app", c.pointer); err != nil {
+				fmt.Fprintf(buf, "error: %v\n", err)
+			}
+			checkGolden(t, "coverage_nested.explain_"+c.name, buf.Bytes(), true)
+		})
+	}
+}
+
+func TestGoldenValidate(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverageMap, err := jsonhelper.ParseCoverageFile("testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the keys which could not be run are reported besides the orphans.
+	coverageMap["azurerm_resource_group"]["tags/KEY"] = []jsonhelper.PropertyCoverage{{Addr: "tags"}}
+	coverageMap["azurerm_resource_group"]["/tags/KEY"] = []jsonhelper.PropertyCoverage{}
+
+	for i := 0; i < renderTimes; i++ {
+		problems, err := validate(schema.ProviderSchema.ResourcesMap, coverageMap, []string{"location"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, format := range []string{"text", "json"} {
+			buf := &bytes.Buffer{}
+			if err := writeProblems(buf, problems, format); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "coverage_nested.validate."+format, buf.Bytes(), i == 0)
+		}
+	}
+}
+
+func checkGolden(t *testing.T, name string, actual []byte, allowUpdate bool) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update && allowUpdate {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file, run `go test -update` to generate it: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("output of %s differs from %s, run `go test -update` if the change is expected", name, path)
+	}
+}
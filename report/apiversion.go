@@ -0,0 +1,54 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// oldestVersionCnt is the count of the oldest api-versions listed in the text output, all of them are in the json output.
+const oldestVersionCnt = 10
+
+// RenderAPIVersions writes the api-versions which the mappings of each resource link to, with the count of properties mapped to each version.
+// The resources mixing versions are marked, the properties mapped only to preview versions are listed, and the oldest versions in use are listed last.
+func RenderAPIVersions(w io.Writer, result *coverage.Result) error {
+	usage := result.VersionUsage()
+	b := &strings.Builder{}
+	for _, res := range usage.Resources {
+		versions := make([]string, 0, len(res.Versions))
+		for _, v := range res.Versions {
+			versions = append(versions, fmt.Sprintf("%s x%d", v, v.PropertyCnt))
+		}
+		mark := ""
+		if res.Mixed {
+			mark = " [mixed]"
+		}
+		fmt.Fprintf(b, "%s: %s%s\n", res.Name, strings.Join(versions, ", "), mark)
+		for _, ptr := range res.PreviewOnly {
+			fmt.Fprintf(b, "  preview only: %s\n", ptr)
+		}
+	}
+	if len(usage.Resources) > 0 {
+		b.WriteString("\n")
+	}
+
+	oldest := usage.Versions
+	if len(oldest) > oldestVersionCnt {
+		oldest = oldest[:oldestVersionCnt]
+	}
+	fmt.Fprintf(b, "oldest api-versions in use (%d of %d):\n", len(oldest), len(usage.Versions))
+	for _, v := range oldest {
+		fmt.Fprintf(b, "  %s: %s\n", v, strings.Join(v.Resources, ", "))
+	}
+	fmt.Fprintf(b, "resources: %d, mixing versions: %d, properties mapped only to preview versions: %d\n", len(usage.Resources), usage.MixedCnt(), usage.PreviewOnlyCnt())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderAPIVersionsJSON writes the api-version usage in json.
+func RenderAPIVersionsJSON(w io.Writer, result *coverage.Result) error {
+	return writeJSON(w, result.VersionUsage())
+}
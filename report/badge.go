@@ -0,0 +1,196 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+const (
+	BadgeScopeTotal     = "total"
+	BadgeScopeResource  = "resource"
+	BadgeScopeService   = "service"
+	BadgeScopeNamespace = "namespace"
+)
+
+// DefaultBadgeThresholds is used when no threshold is configured, see ParseBadgeThresholds for the format.
+const DefaultBadgeThresholds = "0=red,50=orange,75=yellow,90=brightgreen"
+
+type BadgeThreshold struct {
+	Percent float64
+	Color   string
+}
+
+type BadgeOptions struct {
+	// Scope is one of BadgeScopeTotal, BadgeScopeResource, BadgeScopeService and BadgeScopeNamespace.
+	Scope      string
+	Thresholds []BadgeThreshold
+	// Origins overrides the service and namespace of resources, see coverage.Result.Origins.
+	Origins map[string]coverage.Origin
+}
+
+// ParseBadgeThresholds parses thresholds in the format of `percent=color,...`, e.g. `0=red,80=green`, a percent could only be given once.
+// The color of the highest threshold not greater than the percent is used.
+func ParseBadgeThresholds(input string) ([]BadgeThreshold, error) {
+	result := make([]BadgeThreshold, 0)
+	seen := make(map[float64]bool)
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		p, color, ok := strings.Cut(item, "=")
+		if !ok || color == "" {
+			return nil, fmt.Errorf("invalid badge threshold %q, expect `percent=color`", item)
+		}
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid badge threshold %q: %v", item, err)
+		}
+		if seen[v] {
+			return nil, fmt.Errorf("duplicate badge threshold %q", item)
+		}
+		seen[v] = true
+		result = append(result, BadgeThreshold{Percent: v, Color: color})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Percent < result[j].Percent
+	})
+	return result, nil
+}
+
+type shieldsEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+type badge struct {
+	FileName string
+	Label    string
+	Percent  float64
+}
+
+// WriteBadges writes a shields.io endpoint json and a standalone svg badge for each badge of the scope into dir.
+// The total badge is written to `coverage-badge.json` and `coverage-badge.svg`, so it doesn't overwrite a `coverage.json` output written
+// into the same dir, otherwise the files are named after the resource, service or namespace.
+func WriteBadges(dir string, result *coverage.Result, opts BadgeOptions) error {
+	badges := make([]badge, 0)
+	switch opts.Scope {
+	case "", BadgeScopeTotal:
+		badges = append(badges, badge{FileName: "coverage-badge", Label: "coverage", Percent: result.Percent()})
+	case BadgeScopeResource:
+		for _, res := range result.SortedResources() {
+			badges = append(badges, badge{FileName: res.Name, Label: res.Name, Percent: res.Percent()})
+		}
+	case BadgeScopeService, BadgeScopeNamespace:
+		groups, err := result.Rollup(opts.Scope, result.Origins(opts.Origins))
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			badges = append(badges, badge{FileName: g.Name, Label: g.Name, Percent: g.Percent()})
+		}
+	default:
+		return fmt.Errorf("unknown badge scope %q", opts.Scope)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create badge dir: %v", err)
+	}
+
+	for _, b := range badges {
+		color := badgeColor(b.Percent, opts.Thresholds)
+		message := fmt.Sprintf("%.2f%%", b.Percent)
+
+		endpoint, err := json.MarshalIndent(shieldsEndpoint{
+			SchemaVersion: 1,
+			Label:         b.Label,
+			Message:       message,
+			Color:         color,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, b.FileName+".json"), append(endpoint, '\n'), 0644); err != nil {
+			return fmt.Errorf("write badge: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, b.FileName+".svg"), []byte(badgeSVG(b.Label, message, color)), 0644); err != nil {
+			return fmt.Errorf("write badge: %v", err)
+		}
+	}
+	return nil
+}
+
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+func badgeColor(p float64, thresholds []BadgeThreshold) string {
+	color := "lightgrey"
+	for _, t := range thresholds {
+		if p >= t.Percent {
+			color = t.Color
+		}
+	}
+	return color
+}
+
+// badgeSVG renders a flat badge, the text width is estimated since the font is not available here.
+func badgeSVG(label, message, color string) string {
+	fill, ok := badgeColors[color]
+	if !ok {
+		fill = color
+		if !strings.HasPrefix(fill, "#") {
+			fill = "#" + fill
+		}
+	}
+
+	labelWidth := textWidth(label)
+	messageWidth := textWidth(message)
+	width := labelWidth + messageWidth
+	label, message = escapeXML(label), escapeXML(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">
+  <title>%[2]s: %[3]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[4]d" height="20" fill="#555"/>
+    <rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[2]s</text>
+    <text x="%[7]d" y="14">%[2]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[3]s</text>
+    <text x="%[8]d" y="14">%[3]s</text>
+  </g>
+</svg>
+`, width, label, message, labelWidth, messageWidth, escapeXML(fill), labelWidth/2, labelWidth+messageWidth/2)
+}
+
+func textWidth(s string) int {
+	return len(s)*7 + 10
+}
+
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,204 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+func TestParseBadgeThresholds(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []BadgeThreshold
+		err      string
+	}{
+		{
+			input:    DefaultBadgeThresholds,
+			expected: []BadgeThreshold{{0, "red"}, {50, "orange"}, {75, "yellow"}, {90, "brightgreen"}},
+		},
+		// the thresholds are sorted by percent whatever the order they are given in.
+		{
+			input:    " 80=green, 0=red ,40.5=#abcdef,",
+			expected: []BadgeThreshold{{0, "red"}, {40.5, "#abcdef"}, {80, "green"}},
+		},
+		{input: "", expected: []BadgeThreshold{}},
+		{input: "red", err: "expect `percent=color`"},
+		{input: "50=", err: "expect `percent=color`"},
+		{input: "high=red", err: `invalid badge threshold "high=red"`},
+		{input: "0=red,50=orange,50.0=yellow", err: `duplicate badge threshold "50.0=yellow"`},
+	}
+	for _, c := range cases {
+		actual, err := ParseBadgeThresholds(c.input)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("ParseBadgeThresholds(%q) error = %v, expected %q", c.input, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBadgeThresholds(%q): %v", c.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("ParseBadgeThresholds(%q) = %v, expected %v", c.input, actual, c.expected)
+		}
+	}
+}
+
+func TestBadgeColor(t *testing.T) {
+	thresholds, err := ParseBadgeThresholds(DefaultBadgeThresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		percent    float64
+		thresholds []BadgeThreshold
+		expected   string
+	}{
+		{0, thresholds, "red"},
+		{49.99, thresholds, "red"},
+		{50, thresholds, "orange"},
+		{74.99, thresholds, "orange"},
+		{75, thresholds, "yellow"},
+		{90, thresholds, "brightgreen"},
+		{100, thresholds, "brightgreen"},
+		// the percent below the lowest threshold or without thresholds is grey.
+		{10, []BadgeThreshold{{20, "red"}}, "lightgrey"},
+		{10, nil, "lightgrey"},
+	}
+	for _, c := range cases {
+		if actual := badgeColor(c.percent, c.thresholds); actual != c.expected {
+			t.Errorf("badgeColor(%v, %v) = %q, expected %q", c.percent, c.thresholds, actual, c.expected)
+		}
+	}
+}
+
+func TestBadgeSVG(t *testing.T) {
+	svg := badgeSVG(`a<b>&"c"`, "50.00%", "orange")
+	if !strings.Contains(svg, "<title>a&lt;b&gt;&amp;&quot;c&quot;: 50.00%</title>") {
+		t.Errorf("label is not escaped in the svg:\n%s", svg)
+	}
+	if strings.Contains(svg, "a<b>") {
+		t.Errorf("svg has the raw label:\n%s", svg)
+	}
+	if !strings.Contains(svg, `fill="#fe7d37"`) {
+		t.Errorf("svg misses the fill of orange:\n%s", svg)
+	}
+
+	// an unknown color is taken as a hex color.
+	if svg := badgeSVG("coverage", "0.00%", "abcdef"); !strings.Contains(svg, `fill="#abcdef"`) {
+		t.Errorf("svg misses the fill of the hex color:\n%s", svg)
+	}
+	if svg := badgeSVG("coverage", "0.00%", `"/><script>`); strings.Contains(svg, "<script>") {
+		t.Errorf("color is not escaped in the svg:\n%s", svg)
+	}
+}
+
+func TestWriteBadges(t *testing.T) {
+	result := coverage.NewResult()
+	for resType, covered := range map[string]bool{
+		"azurerm_linux_web_app":  true,
+		"azurerm_resource_group": false,
+	} {
+		result.AddProperty(resType, &coverage.Property{Pointer: "/name", Covered: covered})
+	}
+	origins := map[string]coverage.Origin{
+		"azurerm_linux_web_app":  {Service: "web", Namespace: "Microsoft.Web"},
+		"azurerm_resource_group": {Service: "resources", Namespace: "Microsoft.Resources"},
+	}
+
+	cases := []struct {
+		scope    string
+		expected map[string]shieldsEndpoint
+		err      string
+	}{
+		{
+			scope: BadgeScopeTotal,
+			expected: map[string]shieldsEndpoint{
+				"coverage-badge": {SchemaVersion: 1, Label: "coverage", Message: "50.00%", Color: "orange"},
+			},
+		},
+		{
+			scope: "",
+			expected: map[string]shieldsEndpoint{
+				"coverage-badge": {SchemaVersion: 1, Label: "coverage", Message: "50.00%", Color: "orange"},
+			},
+		},
+		{
+			scope: BadgeScopeResource,
+			expected: map[string]shieldsEndpoint{
+				"azurerm_linux_web_app":  {SchemaVersion: 1, Label: "azurerm_linux_web_app", Message: "100.00%", Color: "brightgreen"},
+				"azurerm_resource_group": {SchemaVersion: 1, Label: "azurerm_resource_group", Message: "0.00%", Color: "red"},
+			},
+		},
+		{
+			scope: BadgeScopeService,
+			expected: map[string]shieldsEndpoint{
+				"web":       {SchemaVersion: 1, Label: "web", Message: "100.00%", Color: "brightgreen"},
+				"resources": {SchemaVersion: 1, Label: "resources", Message: "0.00%", Color: "red"},
+			},
+		},
+		{
+			scope: BadgeScopeNamespace,
+			expected: map[string]shieldsEndpoint{
+				"Microsoft.Web":       {SchemaVersion: 1, Label: "Microsoft.Web", Message: "100.00%", Color: "brightgreen"},
+				"Microsoft.Resources": {SchemaVersion: 1, Label: "Microsoft.Resources", Message: "0.00%", Color: "red"},
+			},
+		},
+		{scope: "provider", err: `unknown badge scope "provider"`},
+	}
+
+	thresholds, err := ParseBadgeThresholds(DefaultBadgeThresholds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.scope, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "badges")
+			err := WriteBadges(dir, result, BadgeOptions{Scope: c.scope, Thresholds: thresholds, Origins: origins})
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("error = %v, expected %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := filepath.Glob(filepath.Join(dir, "*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedFiles := make([]string, 0)
+			for name := range c.expected {
+				expectedFiles = append(expectedFiles, filepath.Join(dir, name+".json"), filepath.Join(dir, name+".svg"))
+			}
+			sort.Strings(files)
+			sort.Strings(expectedFiles)
+			if !reflect.DeepEqual(files, expectedFiles) {
+				t.Fatalf("files = %v, expected %v", files, expectedFiles)
+			}
+
+			for name, expected := range c.expected {
+				b, err := os.ReadFile(filepath.Join(dir, name+".json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var endpoint shieldsEndpoint
+				if err := json.Unmarshal(b, &endpoint); err != nil {
+					t.Fatal(err)
+				}
+				if endpoint != expected {
+					t.Errorf("badge of %s = %+v, expected %+v", name, endpoint, expected)
+				}
+			}
+		})
+	}
+}
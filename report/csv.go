@@ -0,0 +1,53 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// RenderCSV writes one row per resource and property, separated by `comma`, e.g. ',' for csv and '\t' for tsv.
+func RenderCSV(w io.Writer, result *coverage.Result, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	if err := cw.Write([]string{"resource", "property", "status", "schema_type", "addr", "ref", "link_github", "ignore_reason"}); err != nil {
+		return err
+	}
+
+	for _, res := range result.SortedResources() {
+		for _, p := range res.SortedProperties() {
+			var addr, ref, link string
+			if p.Coverage != nil {
+				addr, ref, link = p.Coverage.Addr, p.Coverage.Ref, p.Coverage.LinkGithub
+			}
+			if err := cw.Write([]string{res.Name, p.Pointer, status(p), p.SchemaType, addr, ref, link, p.IgnoreReason}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// RenderCSVSummary writes one row per resource with its schema count, coverage count and percent.
+func RenderCSVSummary(w io.Writer, result *coverage.Result, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	if err := cw.Write([]string{"resource", "schema_cnt", "coverage_cnt", "percent"}); err != nil {
+		return err
+	}
+
+	for _, res := range result.SortedResources() {
+		if err := cw.Write([]string{res.Name, fmt.Sprint(res.TotalCnt), fmt.Sprint(res.CoveredCnt), fmt.Sprintf("%.2f", res.Percent())}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+type DiffOptions struct {
+	// Compact only writes one line per changed resource, the changed properties are not listed.
+	Compact bool
+	// Color is one of ColorAuto, ColorAlways and ColorNever.
+	Color string
+}
+
+// RenderDiff writes the changes between two results in plain text, the newly covered properties are marked with `+`,
+// the no longer covered ones with `-`, and the uncovered ones added into or removed from the counts with `>` and `<`.
+func RenderDiff(w io.Writer, delta coverage.Delta, opts DiffOptions) error {
+	color := useColor(w, opts.Color)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "total: %s\n", totalsChange(delta.Base, delta.Head, color))
+	if len(delta.Resources) == 0 {
+		b.WriteString("no resource is changed\n")
+	}
+
+	for _, res := range delta.Resources {
+		if !opts.Compact {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "%s: %s\n", res.Name, totalsChange(res.Base, res.Head, color))
+		if opts.Compact {
+			continue
+		}
+		for _, l := range []struct {
+			mark  string
+			color string
+			props []string
+		}{
+			{"+", ansiGreen, res.NewlyCovered},
+			{"-", ansiRed, res.NoLongerCovered},
+			{">", "", res.Added},
+			{"<", "", res.Removed},
+		} {
+			mark := l.mark
+			if color && l.color != "" {
+				mark = l.color + mark + ansiReset
+			}
+			for _, prop := range l.props {
+				fmt.Fprintf(b, "  %s %s\n", mark, prop)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func totalsChange(base, head coverage.Totals, color bool) string {
+	change := head.Percent() - base.Percent()
+	pct := fmt.Sprintf("%+.2f%%", change)
+	if color {
+		switch {
+		case change > 0:
+			pct = ansiGreen + pct + ansiReset
+		case change < 0:
+			pct = ansiRed + pct + ansiReset
+		}
+	}
+	return fmt.Sprintf("%d/%d (%.2f%%) -> %d/%d (%.2f%%), %s", base.CoveredCnt, base.TotalCnt, base.Percent(), head.CoveredCnt, head.TotalCnt, head.Percent(), pct)
+}
+
+// RenderDiffJSON writes the changes between two results in json.
+func RenderDiffJSON(w io.Writer, delta coverage.Delta) error {
+	return writeJSON(w, delta)
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+func TestTotalsChange(t *testing.T) {
+	cases := []struct {
+		base     coverage.Totals
+		head     coverage.Totals
+		color    bool
+		expected string
+	}{
+		{coverage.Totals{TotalCnt: 4, CoveredCnt: 1}, coverage.Totals{TotalCnt: 4, CoveredCnt: 2}, false, "1/4 (25.00%) -> 2/4 (50.00%), +25.00%"},
+		{coverage.Totals{TotalCnt: 3, CoveredCnt: 2}, coverage.Totals{TotalCnt: 3, CoveredCnt: 1}, false, "2/3 (66.67%) -> 1/3 (33.33%), -33.33%"},
+		{coverage.Totals{TotalCnt: 2, CoveredCnt: 1}, coverage.Totals{TotalCnt: 4, CoveredCnt: 2}, false, "1/2 (50.00%) -> 2/4 (50.00%), +0.00%"},
+		// a resource added or removed has no property on the other side.
+		{coverage.Totals{}, coverage.Totals{TotalCnt: 2, CoveredCnt: 1}, false, "0/0 (0.00%) -> 1/2 (50.00%), +50.00%"},
+		{coverage.Totals{TotalCnt: 4, CoveredCnt: 1}, coverage.Totals{TotalCnt: 4, CoveredCnt: 2}, true, "1/4 (25.00%) -> 2/4 (50.00%), " + ansiGreen + "+25.00%" + ansiReset},
+		{coverage.Totals{TotalCnt: 3, CoveredCnt: 2}, coverage.Totals{TotalCnt: 3, CoveredCnt: 1}, true, "2/3 (66.67%) -> 1/3 (33.33%), " + ansiRed + "-33.33%" + ansiReset},
+		{coverage.Totals{TotalCnt: 2, CoveredCnt: 1}, coverage.Totals{TotalCnt: 2, CoveredCnt: 1}, true, "1/2 (50.00%) -> 1/2 (50.00%), +0.00%"},
+	}
+	for _, c := range cases {
+		if actual := totalsChange(c.base, c.head, c.color); actual != c.expected {
+			t.Errorf("totalsChange(%+v, %+v, %t) = %q, expected %q", c.base, c.head, c.color, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
+)
+
+// RenderExplain writes how a property is matched by the runner in plain text.
+func RenderExplain(w io.Writer, e runner.Explanation) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "resource: %s\n", e.Resource)
+	fmt.Fprintf(b, "pointer:  %s\n\n", e.Pointer)
+
+	b.WriteString("schema path:\n")
+	for i, step := range e.SchemaPath {
+		t := step.Type
+		if step.Elem != "" {
+			t += " of " + step.Elem
+		}
+		fmt.Fprintf(b, "%s%s (%s)\n", strings.Repeat("  ", i+1), step.Name, t)
+	}
+
+	b.WriteString("\nindex expansion:\n")
+	if len(e.Expansions) == 0 {
+		if len(e.SchemaPath) == 1 {
+			b.WriteString("  none, the property is not nested in any block\n")
+		} else {
+			b.WriteString("  none, no block along the path is found on the coverage tree\n")
+		}
+	}
+	for _, exp := range e.Expansions {
+		fmt.Fprintf(b, "  %s: %s\n", exp.Pointer, strings.Join(exp.ChildNames, ", "))
+	}
+
+	fmt.Fprintf(b, "\ndisplay pointer: %s\n", e.DisplayPointer)
+
+	b.WriteString("\ncoverage keys matched:\n")
+	if len(e.CoverageKeys) == 0 {
+		b.WriteString("  none found on the coverage tree\n")
+	}
+	for _, key := range e.CoverageKeys {
+		fmt.Fprintf(b, "  %s\n", key)
+	}
+
+	b.WriteString("\nresult: ")
+	switch {
+	case e.IgnoreRule != "":
+		fmt.Fprintf(b, "ignored by ignore-schema %q\n", e.IgnoreRule)
+	case e.Covered:
+		fmt.Fprintf(b, "covered\n  addr: %s\n  link: %s\n  ref:  %s\n", e.Coverage.Addr, e.Coverage.LinkGithub, e.Coverage.Ref)
+	default:
+		b.WriteString("uncovered\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlTemplate string
+
+type htmlReport struct {
+	TotalCnt   int
+	CoveredCnt int
+	Percent    float64
+	Resources  []htmlResource
+}
+
+type htmlResource struct {
+	Name       string     `json:"name"`
+	TotalCnt   int        `json:"total"`
+	CoveredCnt int        `json:"covered"`
+	Percent    float64    `json:"percent"`
+	Fields     []htmlNode `json:"fields"`
+}
+
+// htmlNode is a field of the property tree, Covered and Link are only set for leaf fields.
+type htmlNode struct {
+	Name     string     `json:"name"`
+	Covered  bool       `json:"covered"`
+	Link     string     `json:"link,omitempty"`
+	Children []htmlNode `json:"children,omitempty"`
+}
+
+// RenderHTML writes a self-contained html report, all the styles, scripts and data are inlined so it could be opened offline.
+func RenderHTML(w io.Writer, result *coverage.Result) error {
+	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	data := htmlReport{
+		TotalCnt:   result.TotalCnt,
+		CoveredCnt: result.CoveredCnt,
+		Percent:    result.Percent(),
+		Resources:  make([]htmlResource, 0, len(result.Resources)),
+	}
+	for _, res := range result.SortedResources() {
+		output, err := GenResourceOutput(res)
+		if err != nil {
+			return err
+		}
+		data.Resources = append(data.Resources, htmlResource{
+			Name:       res.Name,
+			TotalCnt:   res.TotalCnt,
+			CoveredCnt: res.CoveredCnt,
+			Percent:    res.Percent(),
+			Fields:     mergeSchemaNodes(output.CoveredFields, output.UncoveredFields),
+		})
+	}
+
+	return tmpl.Execute(w, data)
+}
+
+// mergeSchemaNodes merges the covered and uncovered trees of a resource into one tree, fields are sorted by name.
+func mergeSchemaNodes(covered, uncovered SchemaNode) []htmlNode {
+	nodes := make(map[string]htmlNode)
+	for name, field := range covered.RootChildren {
+		nodes[name] = htmlNode{Name: name, Covered: true, Link: safeLink(field.GithubUrl)}
+	}
+	for name := range uncovered.RootChildren {
+		nodes[name] = htmlNode{Name: name}
+	}
+
+	blockNames := make(map[string]bool)
+	for name := range covered.Children {
+		blockNames[name] = true
+	}
+	for name := range uncovered.Children {
+		blockNames[name] = true
+	}
+	for name := range blockNames {
+		nodes[name] = htmlNode{
+			Name:     name,
+			Children: mergeSchemaNodes(covered.Children[name], uncovered.Children[name]),
+		}
+	}
+
+	result := make([]htmlNode, 0, len(nodes))
+	for _, n := range nodes {
+		result = append(result, n)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+type InspectOptions struct {
+	// UncoveredOnly only shows the uncovered properties and the blocks containing them.
+	UncoveredOnly bool
+	// Color is one of ColorAuto, ColorAlways and ColorNever.
+	Color string
+}
+
+const (
+	InspectKindBlock    = "block"
+	InspectKindProperty = "property"
+)
+
+// InspectNode is a property or block of the nested schema of a resource, Children are only set for blocks.
+type InspectNode struct {
+	Name string `json:"name"`
+	// Pointer is the display pointer of a property, or the pointer of a block without the folded index.
+	Pointer string `json:"pointer"`
+	// Kind is InspectKindBlock or InspectKindProperty, as Children are omitted for the blocks without any property.
+	Kind string `json:"kind"`
+	// Description is the type of the schema together with whether it's required, optional or computed.
+	Description string `json:"description"`
+	// Status is one of `covered`, `uncovered` and `ignored` for properties, it's empty for blocks.
+	Status       string `json:"status,omitempty"`
+	Addr         string `json:"addr,omitempty"`
+	Link         string `json:"link,omitempty"`
+	IgnoreReason string `json:"ignore_reason,omitempty"`
+	// UncoveredCnt is the count of uncovered properties within the node.
+	UncoveredCnt int           `json:"uncovered_cnt"`
+	Children     []InspectNode `json:"children,omitempty"`
+}
+
+// RenderInspect writes the nested schema of a resource as an indented tree, each property is marked as covered, uncovered or ignored.
+func RenderInspect(w io.Writer, resType string, resource jsonhelper.ResourceJSON, result *coverage.Result, opts InspectOptions) error {
+	color := useColor(w, opts.Color)
+	nodes, err := InspectTree(resType, resource, result)
+	if err != nil {
+		return err
+	}
+
+	b := &strings.Builder{}
+	var totals coverage.Totals
+	if res, ok := result.Resources[resType]; ok {
+		totals = res.Totals
+	}
+	fmt.Fprintf(b, "%s (%d/%d covered, %.2f%%)\n", resType, totals.CoveredCnt, totals.TotalCnt, totals.Percent())
+	writeInspectNodes(b, nodes, 1, opts.UncoveredOnly, color)
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeInspectNodes(b *strings.Builder, nodes []InspectNode, depth int, uncoveredOnly bool, color bool) {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if uncoveredOnly && n.UncoveredCnt == 0 {
+			continue
+		}
+		if n.Kind == InspectKindBlock {
+			fmt.Fprintf(b, "%s▾ %s (%s) %d uncovered\n", indent, n.Name, n.Description, n.UncoveredCnt)
+			writeInspectNodes(b, n.Children, depth+1, uncoveredOnly, color)
+			continue
+		}
+
+		var mark, text string
+		switch n.Status {
+		case "ignored":
+			mark, text = "-", " ignored: "+n.IgnoreReason
+		case "covered":
+			mark, text = "✔", " covered: "+n.Addr
+			if color {
+				mark = ansiGreen + mark + ansiReset
+			}
+		default:
+			mark, text = "✘", " uncovered"
+			if color {
+				mark = ansiRed + mark + ansiReset
+			}
+		}
+		fmt.Fprintf(b, "%s%s %s (%s)%s\n", indent, mark, n.Name, n.Description, text)
+	}
+}
+
+// InspectTree returns the nested schema of a resource with the status of each property in result, the nodes are sorted by name.
+// A property not in result, e.g. of a resource skipped as uncovered, is uncovered.
+func InspectTree(resType string, resource jsonhelper.ResourceJSON, result *coverage.Result) ([]InspectNode, error) {
+	return inspectSchema(resType, resource.Schema, result, nil)
+}
+
+func inspectSchema(resType string, schema map[string]jsonhelper.SchemaJSON, result *coverage.Result, displayPrefix []string) ([]InspectNode, error) {
+	names := make([]string, 0, len(schema))
+	for n := range schema {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	nodes := make([]InspectNode, 0, len(names))
+	for _, n := range names {
+		sch := schema[n]
+		tks := append(append(make([]string, 0, len(displayPrefix)+1), displayPrefix...), n)
+		ptr, err := jsonpointer.New("/" + strings.Join(tks, "/"))
+		if err != nil {
+			return nil, err
+		}
+		node := InspectNode{
+			Name:        n,
+			Pointer:     ptr.String(),
+			Kind:        InspectKindProperty,
+			Description: schemaDescription(sch),
+		}
+
+		if block, ok := sch.Elem.(jsonhelper.ResourceJSON); ok {
+			children, err := inspectSchema(resType, block.Schema, result, append(tks, "0"))
+			if err != nil {
+				return nil, err
+			}
+			node.Kind = InspectKindBlock
+			node.Children = children
+			for _, c := range children {
+				node.UncoveredCnt += c.UncoveredCnt
+			}
+			nodes = append(nodes, node)
+			continue
+		}
+
+		p, ok := result.Property(resType, node.Pointer)
+		switch {
+		case ok && p.Ignored():
+			node.Status = "ignored"
+			node.IgnoreReason = p.IgnoreReason
+		case ok && p.Covered:
+			node.Status = "covered"
+			node.Addr = p.Coverage.Addr
+			node.Link = safeLink(p.Coverage.LinkGithub)
+		default:
+			node.Status = "uncovered"
+			node.UncoveredCnt = 1
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func schemaDescription(sch jsonhelper.SchemaJSON) string {
+	t := sch.Type
+	if elem, ok := sch.Elem.(string); ok {
+		t += " of " + elem
+	}
+
+	attrs := []string{t}
+	switch {
+	case sch.Required:
+		attrs = append(attrs, "required")
+	case sch.Optional:
+		attrs = append(attrs, "optional")
+	}
+	if sch.Computed {
+		attrs = append(attrs, "computed")
+	}
+	return strings.Join(attrs, ", ")
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// RenderJSON writes the covered and uncovered properties of each resource, the ignored properties are skipped.
+func RenderJSON(w io.Writer, result *coverage.Result) error {
+	output := make(map[string]map[string][]string)
+	for k, res := range result.Resources {
+		covered, uncovered := properties(res)
+		output[k] = map[string][]string{
+			"covered_properties":   pointers(covered),
+			"uncovered_properties": pointers(uncovered),
+		}
+	}
+	return writeJSON(w, output)
+}
+
+func pointers(props []*coverage.Property) []string {
+	result := make([]string, 0, len(props))
+	for _, p := range props {
+		result = append(result, p.Pointer)
+	}
+	return result
+}
+
+func writeJSON(w io.Writer, output interface{}) error {
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// RenderJUnit writes a JUnit XML report, each resource is a testsuite and each property is a testcase.
+// Uncovered properties are failures and ignored properties are skipped.
+func RenderJUnit(w io.Writer, result *coverage.Result) error {
+	output := junitTestSuites{
+		Name: "terraform-azurerm-provider-coverage",
+	}
+
+	for _, res := range result.SortedResources() {
+		suite := junitTestSuite{
+			Name: res.Name,
+		}
+
+		for _, p := range res.SortedProperties() {
+			tc := junitTestCase{
+				Name:      p.Pointer,
+				ClassName: res.Name,
+			}
+			switch {
+			case p.Ignored():
+				tc.Skipped = &junitMessage{Message: p.IgnoreReason}
+				suite.Skipped++
+			case p.Covered:
+				tc.SystemOut = fmt.Sprintf("addr: %s\nlink: %s\nref: %s", p.Coverage.Addr, p.Coverage.LinkGithub, p.Coverage.Ref)
+			default:
+				tc.Failure = &junitMessage{Message: "property is not covered by any mapping"}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Tests = len(suite.Cases)
+
+		output.Tests += suite.Tests
+		output.Failures += suite.Failures
+		output.Skipped += suite.Skipped
+		output.Suites = append(output.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(output); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// coverageFile models a resource as a source file, each property is a line and its hit count is the count of mappings.
+type coverageFile struct {
+	Name       string
+	Properties []string
+	Hits       []int
+	CoveredCnt int
+}
+
+func coverageFiles(cov *coverage.Result) []coverageFile {
+	result := make([]coverageFile, 0, len(cov.Resources))
+	for _, res := range cov.SortedResources() {
+		f := coverageFile{
+			Name: res.Name,
+		}
+		for _, p := range res.SortedProperties() {
+			if p.Ignored() {
+				continue
+			}
+			hits := 0
+			if p.Covered {
+				hits = p.MappingCnt
+				// a covered property has at least one mapping
+				if hits == 0 {
+					hits = 1
+				}
+				f.CoveredCnt++
+			}
+			f.Properties = append(f.Properties, p.Pointer)
+			f.Hits = append(f.Hits, hits)
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// RenderLCOV writes a LCOV tracefile, each resource is a file and each property is a line.
+// The properties are also written as functions so the viewers are able to show their names.
+func RenderLCOV(w io.Writer, result *coverage.Result) error {
+	b := &strings.Builder{}
+	b.WriteString("TN:terraform-azurerm-provider-coverage\n")
+	for _, f := range coverageFiles(result) {
+		fmt.Fprintf(b, "SF:%s\n", f.Name)
+		for i, prop := range f.Properties {
+			fmt.Fprintf(b, "FN:%d,%s\n", i+1, prop)
+		}
+		for i, prop := range f.Properties {
+			fmt.Fprintf(b, "FNDA:%d,%s\n", f.Hits[i], prop)
+		}
+		fmt.Fprintf(b, "FNF:%d\nFNH:%d\n", len(f.Properties), f.CoveredCnt)
+		for i, hits := range f.Hits {
+			fmt.Fprintf(b, "DA:%d,%d\n", i+1, hits)
+		}
+		fmt.Fprintf(b, "LF:%d\nLH:%d\nend_of_record\n", len(f.Properties), f.CoveredCnt)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type coberturaCoverage struct {
+	XMLName      xml.Name           `xml:"coverage"`
+	LineRate     string             `xml:"line-rate,attr"`
+	BranchRate   string             `xml:"branch-rate,attr"`
+	LinesCovered int                `xml:"lines-covered,attr"`
+	LinesValid   int                `xml:"lines-valid,attr"`
+	Version      string             `xml:"version,attr"`
+	Timestamp    int64              `xml:"timestamp,attr"`
+	Sources      []string           `xml:"sources>source"`
+	Packages     []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Methods    struct{}        `xml:"methods"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// RenderCobertura writes a Cobertura XML report, each resource is a package and each property is a line.
+func RenderCobertura(w io.Writer, result *coverage.Result) error {
+	output := coberturaCoverage{
+		BranchRate: "0",
+		Version:    "terraform-azurerm-provider-coverage",
+		Sources:    []string{"."},
+	}
+
+	for _, f := range coverageFiles(result) {
+		class := coberturaClass{
+			Name:       f.Name,
+			Filename:   f.Name,
+			LineRate:   lineRate(f.CoveredCnt, len(f.Properties)),
+			BranchRate: "0",
+		}
+		for i, hits := range f.Hits {
+			class.Lines = append(class.Lines, coberturaLine{Number: i + 1, Hits: hits})
+		}
+		output.Packages = append(output.Packages, coberturaPackage{
+			Name:       f.Name,
+			LineRate:   class.LineRate,
+			BranchRate: "0",
+			Classes:    []coberturaClass{class},
+		})
+		output.LinesCovered += f.CoveredCnt
+		output.LinesValid += len(f.Properties)
+	}
+	output.LineRate = lineRate(output.LinesCovered, output.LinesValid)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(output); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func lineRate(covered, total int) string {
+	return fmt.Sprintf("%.4f", coverage.Percent(covered, total)/100)
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// RenderMarkdown writes a markdown report which could be pasted into PRs and wikis.
+func RenderMarkdown(w io.Writer, result *coverage.Result) error {
+	b := &strings.Builder{}
+	resources := result.SortedResources()
+
+	b.WriteString("# Coverage Report\n\n")
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Resources | Properties | Covered | Uncovered | Percent |\n")
+	b.WriteString("| ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(b, "| %d | %d | %d | %d | %.2f%% |\n\n", len(resources), result.TotalCnt, result.CoveredCnt, result.UncoveredCnt(), result.Percent())
+
+	// lowest coverage first, so the resources need attention are on the top.
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].Percent() < resources[j].Percent()
+	})
+
+	b.WriteString("## Resources\n\n")
+	b.WriteString("| Resource | Properties | Covered | Uncovered | Percent |\n")
+	b.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+	for _, res := range resources {
+		fmt.Fprintf(b, "| `%s` | %d | %d | %d | %.2f%% |\n", res.Name, res.TotalCnt, res.CoveredCnt, res.UncoveredCnt(), res.Percent())
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Details\n\n")
+	for _, res := range resources {
+		coveredProps, uncoveredProps := properties(res)
+		fmt.Fprintf(b, "<details>\n<summary><code>%s</code> (%d/%d, %.2f%%)</summary>\n\n", res.Name, res.CoveredCnt, res.TotalCnt, res.Percent())
+		if len(uncoveredProps) > 0 {
+			b.WriteString("**Uncovered properties**\n\n")
+			for _, p := range uncoveredProps {
+				fmt.Fprintf(b, "- `%s`\n", p.Pointer)
+			}
+			b.WriteString("\n")
+		}
+		if len(coveredProps) > 0 {
+			b.WriteString("**Covered properties**\n\n")
+			for _, p := range coveredProps {
+				if link := safeLink(p.Coverage.LinkGithub); link != "" {
+					fmt.Fprintf(b, "- [`%s`](%s)", p.Pointer, link)
+				} else {
+					fmt.Fprintf(b, "- `%s`", p.Pointer)
+				}
+				if p.Coverage.Addr != "" {
+					fmt.Fprintf(b, " `%s`", p.Coverage.Addr)
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("</details>\n\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
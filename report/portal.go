@@ -1,9 +1,13 @@
-package jsonhelper
+package report
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
 )
 
 type ResourceOutput struct {
@@ -14,6 +18,15 @@ type ResourceOutput struct {
 	CoveredPercent  string     `json:"covered_percent"`
 	CoveredFields   SchemaNode `json:"covered_fields"`
 	UncoveredFields SchemaNode `json:"uncovered_fields"`
+	// map[blockPath]BlockOutput, block path is the nested block names joined by "/", e.g. `site_config/application_stack`
+	Blocks map[string]BlockOutput `json:"blocks,omitempty"`
+}
+
+type BlockOutput struct {
+	TotalCnt       int    `json:"total_cnt"`
+	CoveredCnt     int    `json:"covered_cnt"`
+	UncoveredCnt   int    `json:"uncovered_cnt"`
+	CoveredPercent string `json:"covered_percent"`
 }
 
 type SchemaNode struct {
@@ -25,7 +38,7 @@ type FieldOutput struct {
 	GithubUrl string `json:"github_url"`
 }
 
-func (root SchemaNode) fillFields(tks []string, detail *PropertyCoverage) SchemaNode {
+func (root SchemaNode) fillFields(tks []string, detail *jsonhelper.PropertyCoverage) SchemaNode {
 	if len(tks) == 1 {
 		if root.RootChildren == nil {
 			root.RootChildren = make(map[string]FieldOutput, 0)
@@ -54,20 +67,26 @@ func (root SchemaNode) fillFields(tks []string, detail *PropertyCoverage) Schema
 	return root
 }
 
-func GenResourceOutput(name string, fieldsCoverageMap map[string]*PropertyCoverage) (ResourceOutput, error) {
+// GenResourceOutput generates the portal output of a resource, the ignored properties are skipped.
+func GenResourceOutput(res *coverage.Resource) (ResourceOutput, error) {
 	output := ResourceOutput{
-		Name: name,
+		Name: res.Name,
 		CoveredFields: SchemaNode{
 			RootChildren: make(map[string]FieldOutput, 0),
 		},
 		UncoveredFields: SchemaNode{
 			RootChildren: make(map[string]FieldOutput, 0),
 		},
+		Blocks: make(map[string]BlockOutput),
 	}
 
-	for name, detail := range fieldsCoverageMap {
+	for _, p := range res.Properties {
+		if p.Ignored() {
+			continue
+		}
+		detail := p.Coverage
 		output.TotalCnt++
-		jptr, err := jsonpointer.New(name)
+		jptr, err := jsonpointer.New(p.Pointer)
 		if err != nil {
 			return output, err
 		}
@@ -92,6 +111,7 @@ func GenResourceOutput(name string, fieldsCoverageMap map[string]*PropertyCovera
 				//output.UncoveredFields.RootChildren = append(output.UncoveredFields.RootChildren, tkName)
 			}
 		} else {
+			output.rollupBlocks(tks[:len(tks)-1], detail != nil)
 			if detail != nil {
 				output.CoveredCnt++
 				output.CoveredFields = output.CoveredFields.fillFields(tks, detail)
@@ -103,9 +123,29 @@ func GenResourceOutput(name string, fieldsCoverageMap map[string]*PropertyCovera
 	}
 
 	output.CoveredPercent = fmt.Sprintf("%.2f%%", float32(output.CoveredCnt)/float32(output.TotalCnt)*100)
+	for k, block := range output.Blocks {
+		block.CoveredPercent = fmt.Sprintf("%.2f%%", float32(block.CoveredCnt)/float32(block.TotalCnt)*100)
+		output.Blocks[k] = block
+	}
 	return output, nil
 }
 
+// rollupBlocks counts the property into every block it is nested in,
+// e.g. `site_config/application_stack/java_version` is counted into both `site_config` and `site_config/application_stack`.
+func (output *ResourceOutput) rollupBlocks(blockTks []string, covered bool) {
+	for i := range blockTks {
+		path := strings.Join(blockTks[:i+1], "/")
+		block := output.Blocks[path]
+		block.TotalCnt++
+		if covered {
+			block.CoveredCnt++
+		} else {
+			block.UncoveredCnt++
+		}
+		output.Blocks[path] = block
+	}
+}
+
 type PortalDiagnosticOutput struct {
 	TotalCoverPercent string                `json:"total_cover_percent"`
 	TotalFields       int                   `json:"total_fields"`
@@ -120,39 +160,41 @@ type PortalIssueResource struct {
 	CoveredCount int    `json:"covered_count"`
 }
 
-func GenPortalDiagnosticOutput(covCnt, scmCnt map[string]int, ignoreUncoveredResources *bool, coverageMap map[string]map[string][]PropertyCoverage) PortalDiagnosticOutput {
-	totalScm := 0
-	totalCov := 0
-
-	for _, v := range covCnt {
-		totalCov += v
-	}
-
-	for _, v := range scmCnt {
-		totalScm += v
+func GenPortalDiagnosticOutput(result *coverage.Result) PortalDiagnosticOutput {
+	issueRes := make([]PortalIssueResource, 0)
+	for _, issue := range result.Issues() {
+		issueRes = append(issueRes, PortalIssueResource{
+			Name:         issue.Name,
+			StaticsCount: issue.CoveredCnt,
+			CoveredCount: issue.EntryCnt,
+		})
 	}
 
-	resultCnt := scmCnt
-	if *ignoreUncoveredResources {
-		resultCnt = covCnt
+	return PortalDiagnosticOutput{
+		IssueResource:     issueRes,
+		TotalResources:    len(result.Resources),
+		TotalCovered:      result.CoveredCnt,
+		TotalFields:       result.TotalCnt,
+		TotalCoverPercent: fmt.Sprintf("%.2f%%", result.Percent()),
 	}
+}
 
-	issueRes := make([]PortalIssueResource, 0)
-	for k := range resultCnt {
-		if covCnt[k] != len(coverageMap[k]) {
-			issueRes = append(issueRes, PortalIssueResource{
-				Name:         k,
-				StaticsCount: covCnt[k],
-				CoveredCount: len(coverageMap[k]),
-			})
+// RenderPortal writes the resources in the format of coverage portal, the diagnostics are added if diagnostics is true.
+func RenderPortal(w io.Writer, result *coverage.Result, diagnostics bool) error {
+	resources := make([]ResourceOutput, 0, len(result.Resources))
+	for _, res := range result.SortedResources() {
+		rt, err := GenResourceOutput(res)
+		if err != nil {
+			return err
 		}
+		resources = append(resources, rt)
 	}
 
-	return PortalDiagnosticOutput{
-		IssueResource:     issueRes,
-		TotalResources:    len(scmCnt),
-		TotalCovered:      totalCov,
-		TotalFields:       totalScm,
-		TotalCoverPercent: fmt.Sprintf("%.2f%%", float32(totalCov)/float32(totalScm)*100),
+	output := map[string]interface{}{
+		"resources": resources,
+	}
+	if diagnostics {
+		output["diagnostics"] = GenPortalDiagnosticOutput(result)
 	}
+	return writeJSON(w, output)
 }
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+const metricPrefix = "terraform_azurerm_coverage_"
+
+// RenderPrometheus writes the metrics in Prometheus exposition format, which could be collected by node_exporter's textfile collector.
+func RenderPrometheus(w io.Writer, result *coverage.Result) error {
+	b := &strings.Builder{}
+	resources := result.SortedResources()
+
+	orphanResources := make([]string, 0, len(result.Orphans))
+	totalOrphans := 0
+	for resType, keys := range result.Orphans {
+		orphanResources = append(orphanResources, resType)
+		totalOrphans += len(keys)
+	}
+	sort.Strings(orphanResources)
+
+	writeMetricHeader(b, "resource_properties_total", "Count of schema properties of the resource.")
+	for _, res := range resources {
+		fmt.Fprintf(b, "%sresource_properties_total{resource=\"%s\"} %d\n", metricPrefix, escapeLabelValue(res.Name), res.TotalCnt)
+	}
+	writeMetricHeader(b, "resource_properties_covered", "Count of covered schema properties of the resource.")
+	for _, res := range resources {
+		fmt.Fprintf(b, "%sresource_properties_covered{resource=\"%s\"} %d\n", metricPrefix, escapeLabelValue(res.Name), res.CoveredCnt)
+	}
+	writeMetricHeader(b, "resource_properties_ignored", "Count of ignored schema properties of the resource.")
+	for _, res := range resources {
+		fmt.Fprintf(b, "%sresource_properties_ignored{resource=\"%s\"} %d\n", metricPrefix, escapeLabelValue(res.Name), res.IgnoredCnt)
+	}
+	writeMetricHeader(b, "resource_orphaned_entries", "Count of coverage entries of the resource which match no schema property.")
+	for _, resType := range orphanResources {
+		fmt.Fprintf(b, "%sresource_orphaned_entries{resource=\"%s\"} %d\n", metricPrefix, escapeLabelValue(resType), len(result.Orphans[resType]))
+	}
+
+	writeMetricHeader(b, "resources", "Count of resources.")
+	fmt.Fprintf(b, "%sresources %d\n", metricPrefix, len(resources))
+	writeMetricHeader(b, "properties_total", "Count of schema properties of the provider.")
+	fmt.Fprintf(b, "%sproperties_total %d\n", metricPrefix, result.TotalCnt)
+	writeMetricHeader(b, "properties_covered", "Count of covered schema properties of the provider.")
+	fmt.Fprintf(b, "%sproperties_covered %d\n", metricPrefix, result.CoveredCnt)
+	writeMetricHeader(b, "properties_ignored", "Count of ignored schema properties of the provider.")
+	fmt.Fprintf(b, "%sproperties_ignored %d\n", metricPrefix, result.IgnoredCnt)
+	writeMetricHeader(b, "orphaned_entries", "Count of coverage entries of the provider which match no schema property.")
+	fmt.Fprintf(b, "%sorphaned_entries %d\n", metricPrefix, totalOrphans)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMetricHeader(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s%s %s\n", metricPrefix, name, help)
+	fmt.Fprintf(b, "# TYPE %s%s gauge\n", metricPrefix, name)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value as the exposition format requires, only `\`, `"` and line feeds are escaped.
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
@@ -0,0 +1,22 @@
+package report
+
+import "testing"
+
+func TestEscapeLabelValue(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"azurerm_resource_group", "azurerm_resource_group"},
+		{`a\b`, `a\\b`},
+		{`a"b`, `a\"b`},
+		{"a\nb", `a\nb`},
+		// the characters escaped by go but not by the exposition format are kept.
+		{"a\tb☃", "a\tb☃"},
+	}
+	for _, c := range cases {
+		if actual := escapeLabelValue(c.input); actual != c.expected {
+			t.Errorf("escapeLabelValue(%q) = %q, expected %q", c.input, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// Renderer writes a coverage result in a format.
+type Renderer interface {
+	Render(w io.Writer, result *coverage.Result) error
+}
+
+// RendererFunc adapts a function to a Renderer.
+type RendererFunc func(w io.Writer, result *coverage.Result) error
+
+func (f RendererFunc) Render(w io.Writer, result *coverage.Result) error {
+	return f(w, result)
+}
+
+// Options are shared by all the renderers, each renderer only reads the options of its own.
+type Options struct {
+	Table TableOptions
+	// Diagnostics adds the diagnostics into the portal output.
+	Diagnostics bool
+	// Origins overrides the service and namespace of resources in the rollup outputs, see coverage.Result.Origins.
+	Origins map[string]coverage.Origin
+}
+
+// Factory creates a renderer with the options.
+type Factory func(opts Options) Renderer
+
+var factories = make(map[string]Factory)
+
+// Register registers a renderer factory of the format, it panics if the format is registered twice.
+func Register(format string, factory Factory) {
+	if _, ok := factories[format]; ok {
+		panic(fmt.Sprintf("renderer of format %q is already registered", format))
+	}
+	factories[format] = factory
+}
+
+// NewRenderer creates the renderer of the format.
+func NewRenderer(format string, opts Options) (Renderer, error) {
+	factory, ok := factories[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, possible values: %s", format, strings.Join(Formats(), ", "))
+	}
+	return factory(opts), nil
+}
+
+// Formats returns the registered formats sorted lexically.
+func Formats() []string {
+	result := make([]string, 0, len(factories))
+	for format := range factories {
+		result = append(result, format)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// staticFactory is the factory of renderers which take no options.
+func staticFactory(f RendererFunc) Factory {
+	return func(Options) Renderer {
+		return f
+	}
+}
+
+func init() {
+	Register("json", staticFactory(RenderJSON))
+	Register("portal", func(opts Options) Renderer {
+		return RendererFunc(func(w io.Writer, result *coverage.Result) error {
+			return RenderPortal(w, result, opts.Diagnostics)
+		})
+	})
+	Register("table", func(opts Options) Renderer {
+		return RendererFunc(func(w io.Writer, result *coverage.Result) error {
+			return RenderTable(w, result, opts.Table)
+		})
+	})
+	for _, by := range []string{coverage.GroupByService, coverage.GroupByNamespace} {
+		by := by
+		Register(by, func(opts Options) Renderer {
+			return RendererFunc(func(w io.Writer, result *coverage.Result) error {
+				return RenderRollupTable(w, result, by, result.Origins(opts.Origins), opts.Table)
+			})
+		})
+		Register(by+"-json", func(opts Options) Renderer {
+			return RendererFunc(func(w io.Writer, result *coverage.Result) error {
+				return RenderRollupJSON(w, result, by, result.Origins(opts.Origins))
+			})
+		})
+	}
+	Register("markdown", staticFactory(RenderMarkdown))
+	Register("html", staticFactory(RenderHTML))
+	Register("junit", staticFactory(RenderJUnit))
+	Register("cobertura", staticFactory(RenderCobertura))
+	Register("lcov", staticFactory(RenderLCOV))
+	Register("prometheus", staticFactory(RenderPrometheus))
+	Register("api-versions", staticFactory(RenderAPIVersions))
+	Register("api-versions-json", staticFactory(RenderAPIVersionsJSON))
+	for _, sep := range []struct {
+		name  string
+		comma rune
+	}{{"csv", ','}, {"tsv", '\t'}} {
+		comma := sep.comma
+		Register(sep.name, staticFactory(func(w io.Writer, result *coverage.Result) error {
+			return RenderCSV(w, result, comma)
+		}))
+		Register(sep.name+"-summary", staticFactory(func(w io.Writer, result *coverage.Result) error {
+			return RenderCSVSummary(w, result, comma)
+		}))
+	}
+}
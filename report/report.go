@@ -0,0 +1,47 @@
+package report
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+// properties returns the covered and uncovered properties of a resource sorted by pointer, the ignored ones are skipped.
+func properties(res *coverage.Resource) (covered []*coverage.Property, uncovered []*coverage.Property) {
+	for _, p := range res.SortedProperties() {
+		switch {
+		case p.Ignored():
+		case p.Covered:
+			covered = append(covered, p)
+		default:
+			uncovered = append(uncovered, p)
+		}
+	}
+	return covered, uncovered
+}
+
+// status returns one of `covered`, `uncovered` and `ignored`.
+func status(p *coverage.Property) string {
+	switch {
+	case p.Ignored():
+		return "ignored"
+	case p.Covered:
+		return "covered"
+	}
+	return "uncovered"
+}
+
+// safeLink returns the link if it's an absolute http or https url, otherwise an empty string, so the links of the coverage entries
+// could not run scripts in the reports, e.g. `javascript:` links.
+func safeLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return link
+	}
+	return ""
+}
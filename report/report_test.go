@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+func TestSafeLink(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"https://github.com/Azure/azure-rest-api-specs/blob/main/a.json#L1", "https://github.com/Azure/azure-rest-api-specs/blob/main/a.json#L1"},
+		{"http://example.com/a", "http://example.com/a"},
+		{"HTTPS://example.com/a", "HTTPS://example.com/a"},
+		{"", ""},
+		{"javascript:alert(1)", ""},
+		{"JavaScript:alert(1)", ""},
+		{"data:text/html,<script>alert(1)</script>", ""},
+		// relative and scheme-relative links are dropped too, the links of the coverage entries are always absolute.
+		{"specification/web/a.json", ""},
+		{"//example.com/a", ""},
+		{"https:alert(1)", ""},
+	}
+	for _, c := range cases {
+		if actual := safeLink(c.input); actual != c.expected {
+			t.Errorf("safeLink(%q) = %q, expected %q", c.input, actual, c.expected)
+		}
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	result := coverage.NewResult()
+	for ptr, link := range map[string]string{
+		"/name":     "https://example.com/spec.json",
+		"/location": "javascript:alert(1)",
+	} {
+		result.AddProperty("azurerm_test", &coverage.Property{
+			Pointer:  ptr,
+			Covered:  true,
+			Coverage: &jsonhelper.PropertyCoverage{Addr: "addr", LinkGithub: link},
+		})
+	}
+
+	for name, render := range map[string]func(w *bytes.Buffer) error{
+		"html":     func(w *bytes.Buffer) error { return RenderHTML(w, result) },
+		"markdown": func(w *bytes.Buffer) error { return RenderMarkdown(w, result) },
+	} {
+		buf := &bytes.Buffer{}
+		if err := render(buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "https://example.com/spec.json") {
+			t.Errorf("%s report misses the https link", name)
+		}
+		if strings.Contains(buf.String(), "alert(1)") {
+			t.Errorf("%s report has the javascript link", name)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+const (
+	TableSortPercent   = "percent"
+	TableSortUncovered = "uncovered"
+	TableSortName      = "name"
+)
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+const (
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+	ansiGreen  = "\033[32m"
+	ansiBold   = "\033[1m"
+	ansiReset  = "\033[0m"
+)
+
+type TableOptions struct {
+	// Sort is one of TableSortPercent, TableSortUncovered and TableSortName.
+	Sort string
+	// Top limits the count of resources to show, all resources are shown if it's not positive.
+	Top int
+	// Color is one of ColorAuto, ColorAlways and ColorNever.
+	Color string
+}
+
+// tableRow is a row of the table, either a resource or a group of resources.
+type tableRow struct {
+	name string
+	coverage.Totals
+}
+
+// RenderTable writes a human-readable table with aligned columns, the worst covered resources are on the top by default.
+func RenderTable(w io.Writer, result *coverage.Result, opts TableOptions) error {
+	resources := result.SortedResources()
+	if err := SortResources(resources, opts.Sort); err != nil {
+		return err
+	}
+
+	rows := make([]tableRow, 0, len(resources))
+	for _, res := range resources {
+		rows = append(rows, tableRow{name: res.Name, Totals: res.Totals})
+	}
+	return renderTable(w, "resource", rows, result.Totals, opts)
+}
+
+// RenderRollupTable writes the table of the resources rolled up by coverage.GroupByService or coverage.GroupByNamespace.
+func RenderRollupTable(w io.Writer, result *coverage.Result, by string, origins map[string]coverage.Origin, opts TableOptions) error {
+	groups, err := result.Rollup(by, origins)
+	if err != nil {
+		return err
+	}
+	if err := sortByTotals(groups, func(g coverage.Group) coverage.Totals { return g.Totals }, opts.Sort); err != nil {
+		return err
+	}
+
+	rows := make([]tableRow, 0, len(groups))
+	for _, g := range groups {
+		rows = append(rows, tableRow{name: g.Name, Totals: g.Totals})
+	}
+	return renderTable(w, by, rows, result.Totals, opts)
+}
+
+// RenderRollupJSON writes the resources rolled up by coverage.GroupByService or coverage.GroupByNamespace in json, sorted by name.
+func RenderRollupJSON(w io.Writer, result *coverage.Result, by string, origins map[string]coverage.Origin) error {
+	groups, err := result.Rollup(by, origins)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, groups)
+}
+
+// renderTable writes the rows with a footer of total, noun is what a row is, e.g. `resource`.
+func renderTable(w io.Writer, noun string, rows []tableRow, total coverage.Totals, opts TableOptions) error {
+	shown := rows
+	if opts.Top > 0 && opts.Top < len(rows) {
+		shown = rows[:opts.Top]
+	}
+
+	color := useColor(w, opts.Color)
+	label := strings.ToUpper(noun)
+	nameWidth := len(label)
+	for _, row := range shown {
+		if len(row.name) > nameWidth {
+			nameWidth = len(row.name)
+		}
+	}
+
+	b := &strings.Builder{}
+	header := fmt.Sprintf("%-*s  %8s  %8s  %9s  %8s", nameWidth, label, "TOTAL", "COVERED", "UNCOVERED", "PERCENT")
+	if color {
+		header = ansiBold + header + ansiReset
+	}
+	b.WriteString(header + "\n")
+	for _, row := range shown {
+		pct := fmt.Sprintf("%7.2f%%", row.Percent())
+		if color {
+			pct = bandColor(row.Percent()) + pct + ansiReset
+		}
+		fmt.Fprintf(b, "%-*s  %8d  %8d  %9d  %s\n", nameWidth, row.name, row.TotalCnt, row.CoveredCnt, row.UncoveredCnt(), pct)
+	}
+
+	b.WriteString(strings.Repeat("-", nameWidth+2+8+2+8+2+9+2+8) + "\n")
+	if len(shown) < len(rows) {
+		fmt.Fprintf(b, "showing %d of %d %ss\n", len(shown), len(rows), noun)
+	}
+	pct := fmt.Sprintf("%.2f%%", total.Percent())
+	if color {
+		pct = bandColor(total.Percent()) + pct + ansiReset
+	}
+	fmt.Fprintf(b, "total %ss: %d, properties: %d, covered: %d, uncovered: %d, percent: %s\n", noun, len(rows), total.TotalCnt, total.CoveredCnt, total.UncoveredCnt(), pct)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// SortResources sorts the resources sorted by name with one of TableSortPercent, TableSortUncovered and TableSortName,
+// the worst covered resources are on the top, and the ones of the same value are kept sorted by name.
+func SortResources(resources []*coverage.Resource, by string) error {
+	return sortByTotals(resources, func(res *coverage.Resource) coverage.Totals { return res.Totals }, by)
+}
+
+func sortByTotals[T any](items []T, totals func(T) coverage.Totals, by string) error {
+	switch by {
+	case "", TableSortPercent:
+		sort.SliceStable(items, func(i, j int) bool {
+			return totals(items[i]).Percent() < totals(items[j]).Percent()
+		})
+	case TableSortUncovered:
+		sort.SliceStable(items, func(i, j int) bool {
+			return totals(items[i]).UncoveredCnt() > totals(items[j]).UncoveredCnt()
+		})
+	case TableSortName:
+	default:
+		return fmt.Errorf("unknown sort %q", by)
+	}
+	return nil
+}
+
+func bandColor(p float64) string {
+	switch {
+	case p < 50:
+		return ansiRed
+	case p < 80:
+		return ansiYellow
+	default:
+		return ansiGreen
+	}
+}
+
+// useColor follows https://no-color.org, colors are only used for terminals in auto mode.
+func useColor(w io.Writer, mode string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
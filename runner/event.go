@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+)
+
+type EventType string
+
+const (
+	EventResourceStarted   EventType = "resource_started"
+	EventResourceFinished  EventType = "resource_finished"
+	EventPropertyMatched   EventType = "property_matched"
+	EventPropertyUncovered EventType = "property_uncovered"
+	EventPropertyIgnored   EventType = "property_ignored"
+)
+
+// Progress is the position of the resource among the resources of the run, Index starts from 1.
+type Progress struct {
+	Index int
+	Total int
+}
+
+// Event is emitted to Opts.OnEvent during a run, Progress is only set for resource events, and Property is only set for property events.
+type Event struct {
+	Type     EventType
+	Resource string
+	Progress Progress
+	Property *coverage.Property
+}
+
+func (r *runState) emit(e Event) {
+	if r.onEvent != nil {
+		r.onEvent(e)
+	}
+}
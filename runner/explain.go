@@ -0,0 +1,206 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+// Explanation describes how the runner matches a schema property with the coverage entries.
+type Explanation struct {
+	Resource string `json:"resource"`
+	// Pointer is the pointer asked to explain.
+	Pointer    string       `json:"pointer"`
+	SchemaPath []SchemaStep `json:"schema_path"`
+	// DisplayPointer is the pointer of the property in the results.
+	DisplayPointer string `json:"display_pointer"`
+	// Expansions are the indexes found on the coverage tree for the blocks along the schema path, which are folded into `0`.
+	Expansions []Expansion `json:"expansions"`
+	// CoverageKeys are the coverage entries found for the property under all the indexes.
+	CoverageKeys []string                     `json:"coverage_keys"`
+	Covered      bool                         `json:"covered"`
+	Coverage     *jsonhelper.PropertyCoverage `json:"coverage,omitempty"`
+	IgnoreRule   string                       `json:"ignore_rule,omitempty"`
+}
+
+type SchemaStep struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Elem string `json:"elem,omitempty"`
+}
+
+type Expansion struct {
+	Pointer    string   `json:"pointer"`
+	ChildNames []string `json:"child_names"`
+}
+
+type tracedProperty struct {
+	displayPtr string
+	keys       []string
+	ignoreRule string
+}
+
+type tracer struct {
+	expansions []Expansion
+	properties []tracedProperty
+}
+
+func (t *tracer) expand(ptrStr string, childNames []string) {
+	if t == nil {
+		return
+	}
+	t.expansions = append(t.expansions, Expansion{
+		Pointer:    ptrStr,
+		ChildNames: childNames,
+	})
+}
+
+func (t *tracer) record(displayPtrStr string, keys []string, ignoreRule string) {
+	if t == nil {
+		return
+	}
+	t.properties = append(t.properties, tracedProperty{
+		displayPtr: displayPtrStr,
+		keys:       keys,
+		ignoreRule: ignoreRule,
+	})
+}
+
+// Explain walks the resource again with tracing and returns how the property of ptrStr is matched.
+// ptrStr could be either a display pointer like `/site_config/0/always_on`, a pointer with any index like `/site_config/1/always_on`,
+// or a schema path like `site_config/always_on`. The results of the runner are not changed.
+func (r Runner) Explain(resType string, ptrStr string) (*Explanation, error) {
+	res, ok := r.resources[resType]
+	if !ok {
+		return nil, fmt.Errorf("resource %q not found in schema", resType)
+	}
+
+	steps, err := schemaPath(res, ptrStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the events are not emitted for explaining.
+	r.onEvent = nil
+	tr := r.newRunState()
+	tr.tracer = &tracer{}
+
+	if err := tr.handleSchema(tr.resourceContext(resType, res)); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Name)
+	}
+
+	result := &Explanation{
+		Resource:     resType,
+		Pointer:      ptrStr,
+		SchemaPath:   steps,
+		Expansions:   make([]Expansion, 0),
+		CoverageKeys: make([]string, 0),
+	}
+	for _, e := range tr.tracer.expansions {
+		if isSchemaPathPrefix(schemaNames(e.Pointer), names) {
+			result.Expansions = append(result.Expansions, e)
+		}
+	}
+	for _, p := range tr.tracer.properties {
+		if equalStrings(schemaNames(p.displayPtr), names) {
+			result.DisplayPointer = p.displayPtr
+			result.CoverageKeys = append(result.CoverageKeys, p.keys...)
+			result.IgnoreRule = p.ignoreRule
+		}
+	}
+	if p, ok := tr.result.Property(resType, result.DisplayPointer); ok {
+		result.Coverage = p.Coverage
+		result.Covered = p.Covered
+	}
+	return result, nil
+}
+
+// schemaPath walks the schema along the tokens of ptrStr, index tokens of blocks are skipped.
+func schemaPath(res jsonhelper.ResourceJSON, ptrStr string) ([]SchemaStep, error) {
+	if !strings.HasPrefix(ptrStr, "/") {
+		ptrStr = "/" + ptrStr
+	}
+	ptr, err := jsonpointer.New(ptrStr)
+	if err != nil {
+		return nil, err
+	}
+	tks := ptr.DecodedTokens()
+
+	result := make([]SchemaStep, 0)
+	schema := res.Schema
+	for i := 0; i < len(tks); i++ {
+		if schema == nil {
+			// the remaining token is the index or key of a primitive collection.
+			if i == len(tks)-1 && isCollection(result[len(result)-1].Type) {
+				break
+			}
+			return nil, fmt.Errorf("%q is not a block", strings.Join(tks[:i], "/"))
+		}
+
+		sch, ok := schema[tks[i]]
+		if !ok {
+			return nil, fmt.Errorf("property %q not found in schema", strings.Join(tks[:i+1], "/"))
+		}
+		step := SchemaStep{Name: tks[i], Type: sch.Type}
+		schema = nil
+		switch elem := sch.Elem.(type) {
+		case string:
+			step.Elem = elem
+		case jsonhelper.ResourceJSON:
+			schema = elem.Schema
+			if i+1 < len(tks) {
+				if _, ok := schema[tks[i+1]]; !ok {
+					// skip the index
+					i++
+				}
+			}
+		}
+		result = append(result, step)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty pointer %q", ptrStr)
+	}
+	if schema != nil {
+		return nil, fmt.Errorf("%q is a block, please specify a property of it", ptrStr)
+	}
+	return result, nil
+}
+
+// schemaNames returns the schema names of a pointer generated by the runner, in which names and indexes of blocks appear alternately.
+func schemaNames(ptrStr string) []string {
+	ptr, err := jsonpointer.New(ptrStr)
+	if err != nil {
+		return nil
+	}
+	result := make([]string, 0)
+	for i, tk := range ptr.DecodedTokens() {
+		if i%2 == 0 {
+			result = append(result, tk)
+		}
+	}
+	return result
+}
+
+func isSchemaPathPrefix(prefix, path []string) bool {
+	return len(prefix) <= len(path) && equalStrings(prefix, path[:len(prefix)])
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaPath(t *testing.T) {
+	res := testResources()["azurerm_test"]
+	cases := []struct {
+		ptr      string
+		expected []SchemaStep
+		err      string
+	}{
+		{ptr: "/name", expected: []SchemaStep{{Name: "name", Type: typeString}}},
+		{ptr: "name", expected: []SchemaStep{{Name: "name", Type: typeString}}},
+		// the indexes of blocks are optional.
+		{ptr: "/rule/0/nested/1/priority", expected: []SchemaStep{{Name: "rule", Type: "TypeList"}, {Name: "nested", Type: "TypeList"}, {Name: "priority", Type: typeInt}}},
+		{ptr: "rule/nested/priority", expected: []SchemaStep{{Name: "rule", Type: "TypeList"}, {Name: "nested", Type: "TypeList"}, {Name: "priority", Type: typeInt}}},
+		// the trailing index or key of a primitive collection is allowed.
+		{ptr: "/tags/KEY", expected: []SchemaStep{{Name: "tags", Type: "TypeMap", Elem: typeString}}},
+		{ptr: "/zones/0", expected: []SchemaStep{{Name: "zones", Type: "TypeList", Elem: typeString}}},
+		{ptr: "/ports/3", expected: []SchemaStep{{Name: "ports", Type: "TypeSet", Elem: typeInt}}},
+		// but a primitive has no child, and the children of a primitive collection have no child.
+		{ptr: "/name/foo", err: `"name" is not a block`},
+		{ptr: "/rule/0/name/foo", err: `"rule/0/name" is not a block`},
+		{ptr: "/tags/KEY/foo", err: `"tags" is not a block`},
+		{ptr: "/not_a_property", err: `property "not_a_property" not found in schema`},
+		{ptr: "/rule/0", err: `"/rule/0" is a block, please specify a property of it`},
+	}
+	for _, c := range cases {
+		actual, err := schemaPath(res, c.ptr)
+		if c.err != "" {
+			if err == nil || err.Error() != c.err {
+				t.Errorf("schemaPath(%q) error = %v, expected %q", c.ptr, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("schemaPath(%q): %v", c.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("schemaPath(%q) = %+v, expected %+v", c.ptr, actual, c.expected)
+		}
+	}
+}
@@ -5,31 +5,35 @@ import (
 
 	"github.com/go-openapi/jsonpointer"
 	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsontree"
 )
 
 type ResourceContext struct {
-	Name        string // resource type
-	Schema      map[string]jsonhelper.SchemaJSON
-	TokenPrefix []string
-	// display token prefix is less than token prefix
-	// because it might generate duplicate ptr when meet map and array.
+	Name   string // resource type
+	Schema map[string]jsonhelper.SchemaJSON
+	// Nodes are the objects of the current block on the typed coverage tree under all the indexes.
+	Nodes []*jsontree.ObjectNode
+	// display token prefix uses `0` as the index of every block,
+	// so the properties under different indexes are displayed once.
 	DisplayTokenPrefix []string
-	UpdateMapFunc      func(ptrStr string, realPtrStr string) error
 }
 
-func (res ResourceContext) update(schema map[string]jsonhelper.SchemaJSON, newToken []string, newDisplayToken []string) ResourceContext {
-	res.Schema = schema
-	res.TokenPrefix = append(res.TokenPrefix, newToken...)
-	res.DisplayTokenPrefix = append(res.DisplayTokenPrefix, newDisplayToken...)
-	return res
+// Fields returns the field nodes of name of all the current objects.
+func (res ResourceContext) Fields(name string) []jsontree.TypedNode {
+	result := make([]jsontree.TypedNode, 0)
+	for _, n := range res.Nodes {
+		if field, ok := n.Fields[name]; ok {
+			result = append(result, field)
+		}
+	}
+	return result
 }
 
-func (res ResourceContext) JsonPtr(name string) (string, error) {
-	jsonP, err := jsonpointer.New("/" + strings.Join(append(res.TokenPrefix, name), "/"))
-	if err != nil {
-		return "", err
-	}
-	return jsonP.String(), nil
+func (res ResourceContext) update(schema map[string]jsonhelper.SchemaJSON, nodes []*jsontree.ObjectNode, newDisplayToken []string) ResourceContext {
+	res.Schema = schema
+	res.Nodes = nodes
+	res.DisplayTokenPrefix = append(append(make([]string, 0, len(res.DisplayTokenPrefix)+len(newDisplayToken)), res.DisplayTokenPrefix...), newDisplayToken...)
+	return res
 }
 
 func (res ResourceContext) DisplayJsonPtr(name string) (string, error) {
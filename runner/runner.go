@@ -1,11 +1,13 @@
 package runner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 
 	"github.com/go-openapi/jsonpointer"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
 	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
 	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsontree"
 )
@@ -15,18 +17,41 @@ type Opts struct {
 	CoverageMap              map[string]map[string][]jsonhelper.PropertyCoverage
 	IgnoreSchemas            []string
 	IgnoreUncoveredResources bool
+	// Filter limits the run to the resources of these names, all the resources are run if it's empty.
+	// The other resources are still needed to tell the coverage entries of the resources not in the schema, which are reported as orphans.
+	Filter []string
+	// OnEvent is called synchronously on the goroutine of the run for each event, it's optional.
+	OnEvent func(Event)
 }
 
 type Runner struct {
 	resources                map[string]jsonhelper.ResourceJSON
 	coverageMap              map[string]map[string][]jsonhelper.PropertyCoverage
-	ignoreSchemas            []string
+	ignoreRules              []ignoreRule
 	ignoreUncoveredResources bool
-	parsedCoverageTree       map[string]*jsontree.Node
-	// map[resourceType]map[property]coverage_detail
-	coverageResult map[string]map[string]*jsonhelper.PropertyCoverage
-	scmCnt         map[string]int
-	covCnt         map[string]int
+	// filter is nil if all the resources are run.
+	filter             map[string]bool
+	parsedCoverageTree map[string]*jsontree.ObjectNode
+	onEvent            func(Event)
+}
+
+// ignoreRule is an entry of Opts.IgnoreSchemas with its pointer parsed once the runner is created.
+type ignoreRule struct {
+	schema string
+	ptrStr string
+}
+
+// runState holds the state of a single run, the runner itself is never changed after created,
+// so it could be run repeatedly and concurrently.
+type runState struct {
+	Runner
+	result *coverage.Result
+	// map[resourceType]map[coverageKey]matched
+	matched map[string]map[string]bool
+	// walked are the resources whose schema are walked in the run.
+	walked map[string]bool
+	// tracer is only set when explaining a property.
+	tracer *tracer
 }
 
 func NwRunner(opt Opts) (*Runner, error) {
@@ -37,38 +62,69 @@ func NwRunner(opt Opts) (*Runner, error) {
 		return nil, errors.New("coverageMap is nil")
 	}
 
-	parsedCoverageTree := make(map[string]*jsontree.Node)
+	var filter map[string]bool
+	if len(opt.Filter) > 0 {
+		filter = make(map[string]bool)
+		for _, n := range opt.Filter {
+			filter[n] = true
+		}
+	}
+
+	ignoreRules := make([]ignoreRule, 0, len(opt.IgnoreSchemas))
+	for _, ignoreSchema := range opt.IgnoreSchemas {
+		ignorePtr, err := jsonpointer.New("/" + ignoreSchema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore schema %q: %v", ignoreSchema, err)
+		}
+		ignoreRules = append(ignoreRules, ignoreRule{schema: ignoreSchema, ptrStr: ignorePtr.String()})
+	}
+
+	parsedCoverageTree := make(map[string]*jsontree.ObjectNode)
+	interner := jsontree.NewInterner()
 	for n, res := range opt.CoverageMap {
-		rootNode := jsontree.NewNode("/")
-		parsedCoverageTree[n] = &rootNode
+		if filter != nil && !filter[n] {
+			continue
+		}
+		tree := jsontree.NewTree(interner)
 		for prop := range res {
 			ptr, err := jsonpointer.New(prop)
 			if err != nil {
 				return nil, err
 			}
-			rootNode, err = jsontree.ParseJsonPtr(&rootNode, ptr)
-			if err != nil {
-				return nil, err
-			}
+			tree.Insert(ptr)
+		}
+		// the entries of resources not in the schema could not be typed, they are reported as orphans.
+		if resource, ok := opt.Resources[n]; ok {
+			parsedCoverageTree[n] = jsontree.Build(tree.Root, resource.Schema)
 		}
 	}
 
 	return &Runner{
 		resources:                opt.Resources,
 		coverageMap:              opt.CoverageMap,
-		ignoreSchemas:            opt.IgnoreSchemas,
+		ignoreRules:              ignoreRules,
 		ignoreUncoveredResources: opt.IgnoreUncoveredResources,
-		coverageResult:           make(map[string]map[string]*jsonhelper.PropertyCoverage),
-		scmCnt:                   make(map[string]int),
-		covCnt:                   make(map[string]int),
+		filter:                   filter,
 		parsedCoverageTree:       parsedCoverageTree,
+		onEvent:                  opt.OnEvent,
 	}, nil
 }
 
-// details map[resourceType]map[Property]coverageDetail
-// for non-exist property, reference is nil
-func (r Runner) Run() (details map[string]map[string]*jsonhelper.PropertyCoverage, schemaCnt map[string]int, coverageCnt map[string]int, err error) {
-	for resType, res := range r.resources {
+// Run walks the schema of all the resources with the coverage tree, the ignored properties are included in the result but not counted.
+func (r Runner) Run() (*coverage.Result, error) {
+	return r.RunContext(context.Background())
+}
+
+// RunContext is Run with a context, it stops between resources and returns the error of ctx once ctx is done.
+// Resources are walked in the order of their names, so the events of runs are in the same order.
+func (r Runner) RunContext(ctx context.Context) (*coverage.Result, error) {
+	st := r.newRunState()
+
+	resTypes := make([]string, 0, len(r.resources))
+	for resType := range r.resources {
+		if r.filter != nil && !r.filter[resType] {
+			continue
+		}
 		resourceMissed := false
 		if resource, ok := r.coverageMap[resType]; !ok {
 			resourceMissed = true
@@ -79,164 +135,195 @@ func (r Runner) Run() (details map[string]map[string]*jsonhelper.PropertyCoverag
 		if r.ignoreUncoveredResources && resourceMissed {
 			continue
 		}
+		resTypes = append(resTypes, resType)
+	}
+	sort.Strings(resTypes)
 
-		resCtx := ResourceContext{
-			Name:               resType,
-			Schema:             res.Schema,
-			TokenPrefix:        make([]string, 0),
-			DisplayTokenPrefix: make([]string, 0),
+	for i, resType := range resTypes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		if err := r.HandleSchema(resCtx); err != nil {
-			return nil, nil, nil, err
+		progress := Progress{Index: i + 1, Total: len(resTypes)}
+		st.walked[resType] = true
+		st.emit(Event{Type: EventResourceStarted, Resource: resType, Progress: progress})
+		if err := st.handleSchema(r.resourceContext(resType, r.resources[resType])); err != nil {
+			return nil, err
 		}
+		st.emit(Event{Type: EventResourceFinished, Resource: resType, Progress: progress})
+	}
 
+	for resType, res := range st.result.Resources {
+		res.EntryCnt = len(r.coverageMap[resType])
 	}
-	return r.coverageResult, r.scmCnt, r.covCnt, nil
+	st.result.Orphans = st.orphans()
+	return st.result, nil
 }
 
-func (r Runner) HandleNestedSchema(resCtx ResourceContext) func(elem interface{}, name string) error {
-	return func(elem interface{}, name string) error {
-		ptr, err := resCtx.JsonPtr(name)
-		if err != nil {
-			return err
-		}
-
-		possibleNames, _ := r.GetAllChildrenNames(resCtx.Name, ptr)
-		switch t := elem.(type) {
-		case string:
-			if len(possibleNames) == 0 {
-				possibleNames = append(possibleNames, "/0")
-				possibleNames = append(possibleNames, "")
-			}
-			for _, n := range possibleNames {
-				ptr, err := resCtx.JsonPtr(name + "/" + n)
-				if err != nil {
-					return err
-				}
-				displayPtr, err := resCtx.DisplayJsonPtr(name)
-				if err != nil {
-					return err
-				}
-				if err := resCtx.UpdateMapFunc(ptr, displayPtr); err != nil {
-					return err
-				}
-			}
-		case jsonhelper.ResourceJSON:
-			if len(possibleNames) == 0 {
-				possibleNames = append(possibleNames, "0")
-			}
-			for _, n := range possibleNames {
-				if err := r.HandleSchema(resCtx.update(t.Schema, []string{name, n}, []string{name, possibleNames[0]})); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+func (r Runner) newRunState() *runState {
+	return &runState{
+		Runner:  r,
+		result:  coverage.NewResult(),
+		matched: make(map[string]map[string]bool),
+		walked:  make(map[string]bool),
 	}
 }
 
-func (r Runner) HandleSchema(resCtx ResourceContext) error {
-	resCtx.UpdateMapFunc = r.UpdateCoverageResult(resCtx.Name)
-	handleNestedFunc := r.HandleNestedSchema(resCtx)
+func (r Runner) resourceContext(resType string, res jsonhelper.ResourceJSON) ResourceContext {
+	resCtx := ResourceContext{
+		Name:               resType,
+		Schema:             res.Schema,
+		Nodes:              make([]*jsontree.ObjectNode, 0),
+		DisplayTokenPrefix: make([]string, 0),
+	}
+	if root, ok := r.parsedCoverageTree[resType]; ok {
+		resCtx.Nodes = append(resCtx.Nodes, root)
+	}
+	return resCtx
+}
 
-	for n, sch := range resCtx.Schema {
-		switch sch.Type {
-		case jsonhelper.SchemaTypeList,
-			jsonhelper.SchemaTypeSet,
-			jsonhelper.SchemaTypeMap:
-			if err := handleNestedFunc(sch.Elem, n); err != nil {
-				return err
-			}
-		default:
-			ptr, err := resCtx.JsonPtr(n)
-			if err != nil {
-				return err
-			}
-			displayPtr, err := resCtx.DisplayJsonPtr(n)
-			if err != nil {
-				return err
-			}
-			if err := resCtx.UpdateMapFunc(ptr, displayPtr); err != nil {
-				return err
-			}
-		}
+// handleSchema walks the schema and the coverage tree together.
+// The indexes of lists and sets, and the keys of maps are folded, so every property is recorded once under its display pointer,
+// and it's covered if any of the coverage entries under any index matches it.
+func (r *runState) handleSchema(resCtx ResourceContext) error {
+	names := make([]string, 0, len(resCtx.Schema))
+	for n := range resCtx.Schema {
+		names = append(names, n)
 	}
+	sort.Strings(names)
 
-	return nil
-}
+	for _, n := range names {
+		sch := resCtx.Schema[n]
+		nodes := resCtx.Fields(n)
 
-func (r Runner) UpdateCoverageResult(resType string) func(ptrStr string, realPtrStr string) error {
-	return func(ptrStr string, displayPtrStr string) error {
-		if len(r.ignoreSchemas) > 0 {
-			for _, ignoreSchema := range r.ignoreSchemas {
-				ignorePtr, err := jsonpointer.New("/" + ignoreSchema)
-				if err != nil {
-					return err
-				}
-				if ptrStr == ignorePtr.String() {
-					return nil
-				}
-				if displayPtrStr == ignorePtr.String() {
-					return nil
+		if block, ok := sch.Elem.(jsonhelper.ResourceJSON); ok && isCollection(sch.Type) {
+			indexes := make([]*jsontree.ObjectNode, 0)
+			for _, node := range nodes {
+				childNames := make([]string, 0)
+				for _, child := range node.Children() {
+					if obj, ok := child.(*jsontree.ObjectNode); ok {
+						indexes = append(indexes, obj)
+						childNames = append(childNames, obj.Name())
+					}
 				}
+				r.tracer.expand(node.Pointer(), childNames)
 			}
+			if err := r.handleSchema(resCtx.update(block.Schema, indexes, []string{n, "0"})); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if _, ok := r.coverageResult[resType]; !ok {
-			r.coverageResult[resType] = make(map[string]*jsonhelper.PropertyCoverage)
+		// the coverage entries of primitive collections could be either the collection itself, or any of its elements.
+		ptrs := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			_ = jsontree.Walk(node, func(n jsontree.TypedNode) error {
+				ptrs = append(ptrs, n.Pointer())
+				return nil
+			})
 		}
 
-		detail, ok := r.coverageMap[resType][ptrStr]
-		r.UpdatePropExist(resType, displayPtrStr, ok, detail)
-
-		return nil
+		displayPtr, err := resCtx.DisplayJsonPtr(n)
+		if err != nil {
+			return err
+		}
+		r.recordProperty(resCtx.Name, displayPtr, sch.Type, ptrs)
 	}
 
+	return nil
 }
 
-// never use `false` to override `true` on the result map.
-func (r Runner) UpdatePropExist(resType string, propPtr string, exist bool, detail []jsonhelper.PropertyCoverage) {
-	if e, ok := r.coverageResult[resType][propPtr]; ok && e != nil {
-		return
+// recordProperty records a property with the pointers found for it on the coverage tree, only the pointers having coverage entries are taken as matched.
+func (r *runState) recordProperty(resType string, displayPtrStr string, schemaType string, ptrs []string) {
+	matchedPtrs := make([]string, 0)
+	for _, ptrStr := range ptrs {
+		if _, ok := r.coverageMap[resType][ptrStr]; ok {
+			matchedPtrs = append(matchedPtrs, ptrStr)
+		}
 	}
+	sort.Strings(matchedPtrs)
 
-	if exist {
-		r.coverageResult[resType][propPtr] = &detail[0]
-		r.covCnt[resType]++
-	} else {
-		r.coverageResult[resType][propPtr] = nil
+	if _, ok := r.matched[resType]; !ok {
+		r.matched[resType] = make(map[string]bool)
 	}
-	r.scmCnt[resType]++
-}
-
-func (r Runner) GetAllChildrenNames(resType, ptrStr string) ([]string, error) {
-	root, ok := r.parsedCoverageTree[resType]
-	if !ok {
-		return nil, fmt.Errorf("resource type %v not found on coverage tree", resType)
+	for _, ptrStr := range matchedPtrs {
+		r.matched[resType][ptrStr] = true
 	}
 
-	ptr, err := jsonpointer.New(ptrStr)
-	if err != nil {
-		return nil, err
+	prop := &coverage.Property{
+		Pointer:      displayPtrStr,
+		SchemaType:   schemaType,
+		CoverageKeys: matchedPtrs,
 	}
+	for _, rule := range r.ignoreRules {
+		ignored := displayPtrStr == rule.ptrStr
+		for _, ptrStr := range matchedPtrs {
+			ignored = ignored || ptrStr == rule.ptrStr
+		}
+		if ignored {
+			prop.IgnoreReason = fmt.Sprintf("ignore-schema: %s", rule.schema)
+			r.result.AddProperty(resType, prop)
+			r.tracer.record(displayPtrStr, matchedPtrs, rule.schema)
+			r.emit(Event{Type: EventPropertyIgnored, Resource: resType, Property: prop})
+			return
+		}
+	}
+	r.tracer.record(displayPtrStr, matchedPtrs, "")
 
-	cur := root
-	for _, tk := range ptr.DecodedTokens() {
-		if _, ok := cur.Children[tk]; !ok {
-			return nil, fmt.Errorf("no node match %v", tk)
+	if len(matchedPtrs) > 0 {
+		prop.Covered = true
+		prop.Coverage = &r.coverageMap[resType][matchedPtrs[0]][0]
+		mappings := make([]jsonhelper.PropertyCoverage, 0)
+		for _, ptrStr := range matchedPtrs {
+			mappings = append(mappings, r.coverageMap[resType][ptrStr]...)
 		}
+		prop.Mappings = mappings
+		prop.MappingCnt = len(mappings)
+		prop.APIVersions = coverage.APIVersions(mappings)
+	}
+	r.result.AddProperty(resType, prop)
+	if prop.Covered {
+		r.emit(Event{Type: EventPropertyMatched, Resource: resType, Property: prop})
+	} else {
+		r.emit(Event{Type: EventPropertyUncovered, Resource: resType, Property: prop})
+	}
+}
 
-		n, _ := cur.Children[tk]
-		cur = &n
+func isCollection(schemaType string) bool {
+	switch schemaType {
+	case jsonhelper.SchemaTypeList,
+		jsonhelper.SchemaTypeSet,
+		jsonhelper.SchemaTypeMap:
+		return true
 	}
+	return false
+}
 
-	result := make([]string, 0)
-	for k := range cur.Children {
-		result = append(result, k)
+// orphans returns map[resourceType][]coverageKey of the coverage entries which match no schema property,
+// entries of resources which are not in the schema are included. The resources in the schema but not walked are skipped,
+// e.g. the ones filtered out.
+func (r *runState) orphans() map[string][]string {
+	result := make(map[string][]string)
+	for resType, res := range r.coverageMap {
+		if _, ok := r.resources[resType]; ok && !r.walked[resType] {
+			continue
+		}
+		keys := make([]string, 0)
+		for key := range res {
+			if !r.matched[resType][key] {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			result[resType] = keys
+		}
 	}
+	return result
+}
 
-	// do sort to keep result always same
-	sort.Strings(result)
-	return result, nil
+// CoverageTree returns the typed coverage tree of the resource, it's not found if the resource has no coverage entry or is not in the schema.
+func (r Runner) CoverageTree(resType string) (*jsontree.ObjectNode, bool) {
+	root, ok := r.parsedCoverageTree[resType]
+	return root, ok
 }
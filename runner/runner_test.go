@@ -0,0 +1,461 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+const (
+	typeString = "TypeString"
+	typeInt    = "TypeInt"
+	typeBool   = "TypeBool"
+)
+
+func primitive(schemaType string) jsonhelper.SchemaJSON {
+	return jsonhelper.SchemaJSON{Type: schemaType, Optional: true}
+}
+
+func collection(schemaType string, elem interface{}) jsonhelper.SchemaJSON {
+	return jsonhelper.SchemaJSON{Type: schemaType, Optional: true, Elem: elem}
+}
+
+func block(schema map[string]jsonhelper.SchemaJSON) jsonhelper.SchemaJSON {
+	return collection(jsonhelper.SchemaTypeList, jsonhelper.ResourceJSON{Schema: schema})
+}
+
+func mapping(addr string) []jsonhelper.PropertyCoverage {
+	return []jsonhelper.PropertyCoverage{{Addr: addr}}
+}
+
+// testResources returns a schema with nested blocks, primitive collections and a resource without coverage entries.
+func testResources() map[string]jsonhelper.ResourceJSON {
+	return map[string]jsonhelper.ResourceJSON{
+		"azurerm_test": {Schema: map[string]jsonhelper.SchemaJSON{
+			"name":     primitive(typeString),
+			"location": primitive(typeString),
+			"comment":  primitive(typeString),
+			"tags":     collection(jsonhelper.SchemaTypeMap, typeString),
+			"zones":    collection(jsonhelper.SchemaTypeList, typeString),
+			"ports":    collection(jsonhelper.SchemaTypeSet, typeInt),
+			"rule": block(map[string]jsonhelper.SchemaJSON{
+				"name": primitive(typeString),
+				"nested": block(map[string]jsonhelper.SchemaJSON{
+					"priority": primitive(typeInt),
+					"enabled":  primitive(typeBool),
+				}),
+			}),
+		}},
+		"azurerm_other": {Schema: map[string]jsonhelper.SchemaJSON{
+			"name": primitive(typeString),
+		}},
+		"azurerm_uncovered": {Schema: map[string]jsonhelper.SchemaJSON{
+			"name": primitive(typeString),
+		}},
+	}
+}
+
+func testCoverageMap() map[string]map[string][]jsonhelper.PropertyCoverage {
+	return map[string]map[string][]jsonhelper.PropertyCoverage{
+		"azurerm_test": {
+			"/name":     mapping("name"),
+			"/location": mapping("location"),
+			// the element of a primitive map or list covers the collection.
+			"/tags/KEY": mapping("tags"),
+			"/zones":    mapping("zones"),
+			"/ports/3":  mapping("ports"),
+			// the entries under several indexes are folded into the same property.
+			"/rule/0/name":              mapping("rules[].name"),
+			"/rule/1/name":              mapping("rules[].name"),
+			"/rule/1/nested/2/priority": append(mapping("rules[].nested[].priority"), mapping("rules[].nested[].priority")...),
+			"/rule/0/not_a_property":    mapping("unknown"),
+		},
+		"azurerm_other": {
+			"/name":         mapping("name"),
+			"/not_property": mapping("unknown"),
+		},
+		"azurerm_not_in_schema": {
+			"/name": mapping("name"),
+		},
+	}
+}
+
+func newTestRunner(t *testing.T, opts Opts) *Runner {
+	t.Helper()
+	if opts.Resources == nil {
+		opts.Resources = testResources()
+	}
+	if opts.CoverageMap == nil {
+		opts.CoverageMap = testCoverageMap()
+	}
+	r, err := NwRunner(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRunOrphans(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     Opts
+		expected map[string][]string
+	}{
+		{
+			name: "all resources",
+			expected: map[string][]string{
+				"azurerm_test":          {"/rule/0/not_a_property"},
+				"azurerm_other":         {"/not_property"},
+				"azurerm_not_in_schema": {"/name"},
+			},
+		},
+		{
+			name: "filtered",
+			opts: Opts{Filter: []string{"azurerm_test"}},
+			// the entries of the resources filtered out are not orphans, but the ones of the resources not in the schema are.
+			expected: map[string][]string{
+				"azurerm_test":          {"/rule/0/not_a_property"},
+				"azurerm_not_in_schema": {"/name"},
+			},
+		},
+		{
+			name: "ignore uncovered resources",
+			opts: Opts{IgnoreUncoveredResources: true},
+			expected: map[string][]string{
+				"azurerm_test":          {"/rule/0/not_a_property"},
+				"azurerm_other":         {"/not_property"},
+				"azurerm_not_in_schema": {"/name"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := newTestRunner(t, c.opts).Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Orphans, c.expected) {
+				t.Errorf("orphans = %v, expected %v", result.Orphans, c.expected)
+			}
+		})
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	result, err := newTestRunner(t, Opts{Filter: []string{"azurerm_test", "azurerm_uncovered"}}).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, res := range result.SortedResources() {
+		names = append(names, res.Name)
+	}
+	if expected := []string{"azurerm_test", "azurerm_uncovered"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("resources = %v, expected %v", names, expected)
+	}
+	if _, ok := result.Resources["azurerm_other"]; ok {
+		t.Errorf("the resource filtered out is run")
+	}
+}
+
+// checkTotals checks the totals of the resources and the provider are the counts of the properties listed.
+func checkTotals(t *testing.T, result *coverage.Result) {
+	t.Helper()
+	var provider coverage.Totals
+	for _, res := range result.SortedResources() {
+		var counted coverage.Totals
+		for _, p := range res.Properties {
+			switch {
+			case p.Ignored():
+				counted.IgnoredCnt++
+			case p.Covered:
+				counted.TotalCnt++
+				counted.CoveredCnt++
+			default:
+				counted.TotalCnt++
+			}
+		}
+		if res.Totals != counted {
+			t.Errorf("totals of %s = %+v, expected the counts of its properties %+v", res.Name, res.Totals, counted)
+		}
+		provider.TotalCnt += counted.TotalCnt
+		provider.CoveredCnt += counted.CoveredCnt
+		provider.IgnoredCnt += counted.IgnoredCnt
+	}
+	if result.Totals != provider {
+		t.Errorf("totals of the provider = %+v, expected %+v", result.Totals, provider)
+	}
+}
+
+func TestRunProperties(t *testing.T) {
+	result, err := newTestRunner(t, Opts{
+		IgnoreSchemas: []string{"location", "rule/0/nested/0/enabled"},
+	}).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTotals(t, result)
+
+	type expectedProperty struct {
+		status     string
+		keys       []string
+		mappingCnt int
+	}
+	// every property is counted once under its display pointer, whatever the count of indexes or map keys in the coverage entries.
+	expected := map[string]expectedProperty{
+		"/comment":  {status: "uncovered"},
+		"/location": {status: "ignored", keys: []string{"/location"}},
+		"/name":     {status: "covered", keys: []string{"/name"}, mappingCnt: 1},
+		// the entries of a primitive collection or of any of its elements cover the collection.
+		"/ports": {status: "covered", keys: []string{"/ports/3"}, mappingCnt: 1},
+		"/tags":  {status: "covered", keys: []string{"/tags/KEY"}, mappingCnt: 1},
+		"/zones": {status: "covered", keys: []string{"/zones"}, mappingCnt: 1},
+		// the entries under several indexes are folded into one property, with the mappings of all of them.
+		"/rule/0/name":              {status: "covered", keys: []string{"/rule/0/name", "/rule/1/name"}, mappingCnt: 2},
+		"/rule/0/nested/0/priority": {status: "covered", keys: []string{"/rule/1/nested/2/priority"}, mappingCnt: 2},
+		"/rule/0/nested/0/enabled":  {status: "ignored"},
+	}
+
+	res := result.Resources["azurerm_test"]
+	if len(res.Properties) != len(expected) {
+		t.Errorf("count of properties = %d, expected %d", len(res.Properties), len(expected))
+	}
+	for ptr, e := range expected {
+		p, ok := res.Properties[ptr]
+		if !ok {
+			t.Errorf("property %s not found", ptr)
+			continue
+		}
+		status := "uncovered"
+		switch {
+		case p.Ignored():
+			status = "ignored"
+		case p.Covered:
+			status = "covered"
+		}
+		if status != e.status {
+			t.Errorf("%s is %s, expected %s", ptr, status, e.status)
+		}
+		if len(p.CoverageKeys) > 0 || len(e.keys) > 0 {
+			if !reflect.DeepEqual(p.CoverageKeys, e.keys) {
+				t.Errorf("coverage keys of %s = %v, expected %v", ptr, p.CoverageKeys, e.keys)
+			}
+		}
+		if p.MappingCnt != e.mappingCnt || len(p.Mappings) != e.mappingCnt {
+			t.Errorf("mapping count of %s = %d with %d mappings, expected %d", ptr, p.MappingCnt, len(p.Mappings), e.mappingCnt)
+		}
+	}
+
+	expectedTotals := coverage.Totals{TotalCnt: 7, CoveredCnt: 6, IgnoredCnt: 2}
+	if res.Totals != expectedTotals {
+		t.Errorf("totals = %+v, expected %+v", res.Totals, expectedTotals)
+	}
+	// the entry matching no property is counted in the entries but not in the covered properties, and the entries of the same property are
+	// counted separately, so both make the resource an issue.
+	if res.EntryCnt != 9 {
+		t.Errorf("entry count = %d, expected 9", res.EntryCnt)
+	}
+	expectedIssues := []coverage.Issue{
+		{Name: "azurerm_other", CoveredCnt: 1, EntryCnt: 2},
+		{Name: "azurerm_test", CoveredCnt: 6, EntryCnt: 9},
+	}
+	if issues := result.Issues(); !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("issues = %+v, expected %+v", issues, expectedIssues)
+	}
+}
+
+func TestRunIgnoreSchemas(t *testing.T) {
+	cases := []struct {
+		rule     string
+		expected []string
+	}{
+		{"location", []string{"/location"}},
+		// a rule is the display pointer of the property, in which the indexes of blocks are 0.
+		{"rule/0/nested/0/enabled", []string{"/rule/0/nested/0/enabled"}},
+		{"rule/0/nested/0/priority", []string{"/rule/0/nested/0/priority"}},
+		// or one of its coverage keys.
+		{"rule/1/name", []string{"/rule/0/name"}},
+		{"tags/KEY", []string{"/tags"}},
+		// a schema path without the indexes of blocks matches nothing.
+		{"rule/nested/enabled", []string{}},
+		{"rule/name", []string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.rule, func(t *testing.T) {
+			result, err := newTestRunner(t, Opts{IgnoreSchemas: []string{c.rule}}).Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkTotals(t, result)
+			ignored := make([]string, 0)
+			for _, p := range result.Resources["azurerm_test"].SortedProperties() {
+				if p.Ignored() {
+					ignored = append(ignored, p.Pointer)
+					if expected := "ignore-schema: " + c.rule; p.IgnoreReason != expected {
+						t.Errorf("ignore reason of %s = %q, expected %q", p.Pointer, p.IgnoreReason, expected)
+					}
+				}
+			}
+			if !reflect.DeepEqual(ignored, c.expected) {
+				t.Errorf("ignored = %v, expected %v", ignored, c.expected)
+			}
+		})
+	}
+}
+
+func TestRunTotalsOfProvider(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("../testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"../testdata/coverage.json", "../testdata/coverage_nested.json"} {
+		coverageMap, err := jsonhelper.ParseCoverageFile(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := newTestRunner(t, Opts{
+			Resources:     schema.ProviderSchema.ResourcesMap,
+			CoverageMap:   coverageMap,
+			IgnoreSchemas: []string{"location"},
+		}).Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkTotals(t, result)
+
+		// every property of the schema is counted once rather than once for each index found in the coverage entries,
+		// e.g. linux_web_app counts the 237 properties of its schema, 236 of which are not ignored in the goldens.
+		for _, res := range result.Resources {
+			if cnt := countSchemaProperties(schema.ProviderSchema.ResourcesMap[res.Name].Schema); res.TotalCnt+res.IgnoredCnt != cnt {
+				t.Errorf("%s counts %d properties, expected %d of its schema", res.Name, res.TotalCnt+res.IgnoredCnt, cnt)
+			}
+		}
+	}
+}
+
+func countSchemaProperties(schema map[string]jsonhelper.SchemaJSON) int {
+	cnt := 0
+	for _, sch := range schema {
+		if block, ok := sch.Elem.(jsonhelper.ResourceJSON); ok {
+			cnt += countSchemaProperties(block.Schema)
+			continue
+		}
+		cnt++
+	}
+	return cnt
+}
+
+func TestRunRepeatedly(t *testing.T) {
+	r := newTestRunner(t, Opts{IgnoreSchemas: []string{"location"}})
+	first, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		result, err := r.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, first) {
+			t.Fatalf("run %d differs from the first run", i+2)
+		}
+		if result == first {
+			t.Fatalf("run %d returns the result of the first run", i+2)
+		}
+	}
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make([]string, 0)
+	r := newTestRunner(t, Opts{
+		OnEvent: func(e Event) {
+			switch e.Type {
+			case EventResourceStarted:
+				started = append(started, e.Resource)
+			case EventResourceFinished:
+				// the resource being walked is finished, the run stops before the next one.
+				cancel()
+			}
+		},
+	})
+
+	result, err := r.RunContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, expected %v", err, context.Canceled)
+	}
+	if result != nil {
+		t.Errorf("result = %v, expected nil", result)
+	}
+	if expected := []string{"azurerm_other"}; !reflect.DeepEqual(started, expected) {
+		t.Errorf("started = %v, expected %v", started, expected)
+	}
+
+	if _, err := r.RunContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("error of the run with a done context = %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestRunEvents(t *testing.T) {
+	events := make([]Event, 0)
+	r := newTestRunner(t, Opts{
+		IgnoreSchemas: []string{"location"},
+		OnEvent: func(e Event) {
+			events = append(events, e)
+		},
+	})
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the resources are walked in the order of their names, each one is started, has the events of its properties, and is finished.
+	resources := []string{"azurerm_other", "azurerm_test", "azurerm_uncovered"}
+	i := 0
+	for index, resType := range resources {
+		progress := Progress{Index: index + 1, Total: len(resources)}
+		if i >= len(events) || events[i].Type != EventResourceStarted || events[i].Resource != resType || events[i].Progress != progress {
+			t.Fatalf("event %d = %+v, expected %s started with %+v", i, events[i], resType, progress)
+		}
+		i++
+
+		propertyCnt := 0
+		for ; i < len(events) && events[i].Type != EventResourceFinished; i++ {
+			e := events[i]
+			if e.Resource != resType || e.Property == nil {
+				t.Fatalf("event %d = %+v, expected a property event of %s", i, e, resType)
+			}
+			var expected EventType
+			switch {
+			case e.Property.Ignored():
+				expected = EventPropertyIgnored
+			case e.Property.Covered:
+				expected = EventPropertyMatched
+			default:
+				expected = EventPropertyUncovered
+			}
+			if e.Type != expected {
+				t.Errorf("event of %s is %s, expected %s", e.Property.Pointer, e.Type, expected)
+			}
+			propertyCnt++
+		}
+		if propertyCnt != len(result.Resources[resType].Properties) {
+			t.Errorf("%s has %d property events, expected %d", resType, propertyCnt, len(result.Resources[resType].Properties))
+		}
+
+		if i >= len(events) || events[i].Resource != resType || events[i].Progress != progress {
+			t.Fatalf("expected %s finished with %+v", resType, progress)
+		}
+		i++
+	}
+	if i != len(events) {
+		t.Errorf("%d events after the last resource is finished", len(events)-i)
+	}
+}
@@ -0,0 +1,261 @@
+// Package server serves a coverage result over HTTP, with JSON endpoints and an embedded HTML UI built on them.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
+)
+
+//go:embed ui/index.html
+var indexHTML []byte
+
+// Snapshot is a run of the schema and coverage files, it's never changed once created.
+type Snapshot struct {
+	Resources map[string]jsonhelper.ResourceJSON
+	Runner    *runner.Runner
+	Result    *coverage.Result
+	LoadedAt  time.Time
+}
+
+// NewSnapshot runs the coverage of all the resources, the runner is kept to explain properties.
+func NewSnapshot(resources map[string]jsonhelper.ResourceJSON, coverageMap map[string]map[string][]jsonhelper.PropertyCoverage, ignoreSchemas []string, ignoreUncoveredResources bool) (*Snapshot, error) {
+	r, err := runner.NwRunner(runner.Opts{
+		Resources:                resources,
+		CoverageMap:              coverageMap,
+		IgnoreSchemas:            ignoreSchemas,
+		IgnoreUncoveredResources: ignoreUncoveredResources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.Run()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Resources: resources,
+		Runner:    r,
+		Result:    result,
+		LoadedAt:  time.Now(),
+	}, nil
+}
+
+// Server serves the latest snapshot, which could be replaced by Update while serving.
+//
+//	GET /                                         the HTML UI
+//	GET /api/summary                              the totals of the provider
+//	GET /api/resources?q=&sort=&limit=            the resources whose names contain q, sorted by percent, uncovered or name
+//	GET /api/resources/{name}                     the totals and the property tree of a resource
+//	GET /api/resources/{name}/explain?pointer=    how a property of a resource is matched
+type Server struct {
+	mu       sync.RWMutex
+	snapshot *Snapshot
+	mux      *http.ServeMux
+}
+
+func New(snapshot *Snapshot) *Server {
+	s := &Server{
+		snapshot: snapshot,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/api/summary", s.handleSummary)
+	s.mux.HandleFunc("/api/resources", s.handleResources)
+	s.mux.HandleFunc("/api/resources/", s.handleResource)
+	return s
+}
+
+// Update replaces the snapshot served, the requests being served keep the snapshot they started with.
+func (s *Server) Update(snapshot *Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = snapshot
+}
+
+func (s *Server) current() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", r.URL.Path))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexHTML)
+}
+
+type summary struct {
+	coverage.Totals
+	UncoveredCnt int       `json:"uncovered_cnt"`
+	Percent      float64   `json:"percent"`
+	ResourceCnt  int       `json:"resource_cnt"`
+	OrphanCnt    int       `json:"orphan_cnt"`
+	IssueCnt     int       `json:"issue_cnt"`
+	LoadedAt     time.Time `json:"loaded_at"`
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.current()
+	result := snapshot.Result
+	orphanCnt := 0
+	for _, keys := range result.Orphans {
+		orphanCnt += len(keys)
+	}
+	writeJSON(w, summary{
+		Totals:       result.Totals,
+		UncoveredCnt: result.UncoveredCnt(),
+		Percent:      result.Percent(),
+		ResourceCnt:  len(result.Resources),
+		OrphanCnt:    orphanCnt,
+		IssueCnt:     len(result.Issues()),
+		LoadedAt:     snapshot.LoadedAt,
+	})
+}
+
+type resourceSummary struct {
+	Name string `json:"name"`
+	coverage.Totals
+	UncoveredCnt int     `json:"uncovered_cnt"`
+	Percent      float64 `json:"percent"`
+	EntryCnt     int     `json:"entry_cnt"`
+}
+
+func newResourceSummary(res *coverage.Resource) resourceSummary {
+	return resourceSummary{
+		Name:         res.Name,
+		Totals:       res.Totals,
+		UncoveredCnt: res.UncoveredCnt(),
+		Percent:      res.Percent(),
+		EntryCnt:     res.EntryCnt,
+	}
+}
+
+func (s *Server) handleResources(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	q := strings.ToLower(query.Get("q"))
+	resources := make([]*coverage.Resource, 0)
+	for _, res := range s.current().Result.SortedResources() {
+		if strings.Contains(strings.ToLower(res.Name), q) {
+			resources = append(resources, res)
+		}
+	}
+	if err := report.SortResources(resources, query.Get("sort")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if limit > 0 && limit < len(resources) {
+		resources = resources[:limit]
+	}
+
+	result := make([]resourceSummary, 0, len(resources))
+	for _, res := range resources {
+		result = append(result, newResourceSummary(res))
+	}
+	writeJSON(w, result)
+}
+
+type resourceDetail struct {
+	resourceSummary
+	// Orphans are the coverage keys of the resource which match no schema property.
+	Orphans    []string             `json:"orphans"`
+	Properties []report.InspectNode `json:"properties"`
+}
+
+// handleResource serves both `/api/resources/{name}` and `/api/resources/{name}/explain`.
+func (s *Server) handleResource(w http.ResponseWriter, r *http.Request) {
+	// the escaped path is split, so an escaped `/` in the name is not taken as a separator, and the name is unescaped only once.
+	name, action, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/api/resources/"), "/")
+	name, err := url.PathUnescape(name)
+	if err != nil || name == "" || (action != "" && action != "explain") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", r.URL.Path))
+		return
+	}
+
+	snapshot := s.current()
+	schema, ok := snapshot.Resources[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("resource %q not found in schema", name))
+		return
+	}
+
+	if action == "explain" {
+		ptr := r.URL.Query().Get("pointer")
+		if ptr == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("pointer is required"))
+			return
+		}
+		explanation, err := snapshot.Runner.Explain(name, ptr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, explanation)
+		return
+	}
+
+	// the resources skipped as uncovered are not in the result, all their properties are uncovered.
+	res, ok := snapshot.Result.Resources[name]
+	if !ok {
+		res = &coverage.Resource{Name: name}
+	}
+	nodes, err := report.InspectTree(name, schema, snapshot.Result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	orphans := snapshot.Result.Orphans[name]
+	if orphans == nil {
+		orphans = make([]string, 0)
+	}
+	writeJSON(w, resourceDetail{
+		resourceSummary: newResourceSummary(res),
+		Orphans:         orphans,
+		Properties:      nodes,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
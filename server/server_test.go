@@ -0,0 +1,289 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/runner"
+)
+
+var (
+	schemaOnce sync.Once
+	schema     *jsonhelper.ProviderWrapper
+	schemaErr  error
+)
+
+func loadResources(t *testing.T) map[string]jsonhelper.ResourceJSON {
+	t.Helper()
+	schemaOnce.Do(func() {
+		schema, schemaErr = jsonhelper.ParseSchema("../testdata/schema.json")
+	})
+	if schemaErr != nil {
+		t.Fatal(schemaErr)
+	}
+	return schema.ProviderSchema.ResourcesMap
+}
+
+// newTestSnapshot runs testdata/coverage_nested.json with `location` ignored, the resources without coverage entries are skipped.
+func newTestSnapshot(t *testing.T, modify func(map[string]map[string][]jsonhelper.PropertyCoverage)) *Snapshot {
+	t.Helper()
+	coverageMap, err := jsonhelper.ParseCoverageFile("../testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modify != nil {
+		modify(coverageMap)
+	}
+	snapshot, err := NewSnapshot(loadResources(t), coverageMap, []string{"location"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+// serve serves the request and decodes the json response into v if v is not nil.
+func serve(t *testing.T, s *Server, method, target string, expectedCode int, v interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	if rec.Code != expectedCode {
+		t.Fatalf("%s %s: status %d, expected %d: %s", method, target, rec.Code, expectedCode, rec.Body.String())
+	}
+	if v == nil {
+		return
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("%s %s: content type %q", method, target, ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	snapshot := newTestSnapshot(t, nil)
+	s := New(snapshot)
+
+	var actual summary
+	serve(t, s, http.MethodGet, "/api/summary", http.StatusOK, &actual)
+	if actual.Totals != snapshot.Result.Totals {
+		t.Errorf("totals = %+v, expected %+v", actual.Totals, snapshot.Result.Totals)
+	}
+	if actual.UncoveredCnt != snapshot.Result.UncoveredCnt() || actual.Percent != snapshot.Result.Percent() {
+		t.Errorf("uncovered = %d, percent = %v", actual.UncoveredCnt, actual.Percent)
+	}
+	// azurerm_linux_web_app and azurerm_resource_group, the orphans are `/site_config/0/not_a_property` and the entry of azurerm_not_in_schema.
+	if actual.ResourceCnt != 2 || actual.OrphanCnt != 2 {
+		t.Errorf("resource_cnt = %d, orphan_cnt = %d, expected 2 and 2", actual.ResourceCnt, actual.OrphanCnt)
+	}
+	if actual.IssueCnt != len(snapshot.Result.Issues()) {
+		t.Errorf("issue_cnt = %d, expected %d", actual.IssueCnt, len(snapshot.Result.Issues()))
+	}
+	if !actual.LoadedAt.Equal(snapshot.LoadedAt) {
+		t.Errorf("loaded_at = %v, expected %v", actual.LoadedAt, snapshot.LoadedAt)
+	}
+}
+
+func TestResources(t *testing.T) {
+	snapshot := newTestSnapshot(t, nil)
+	s := New(snapshot)
+	webApp := snapshot.Result.Resources["azurerm_linux_web_app"]
+	resourceGroup := snapshot.Result.Resources["azurerm_resource_group"]
+	worst, best := "azurerm_linux_web_app", "azurerm_resource_group"
+	if webApp.Percent() > resourceGroup.Percent() {
+		worst, best = best, worst
+	}
+	mostUncovered, leastUncovered := "azurerm_linux_web_app", "azurerm_resource_group"
+	if webApp.UncoveredCnt() < resourceGroup.UncoveredCnt() {
+		mostUncovered, leastUncovered = leastUncovered, mostUncovered
+	}
+
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{worst, best}},
+		{"?sort=percent", []string{worst, best}},
+		{"?sort=uncovered", []string{mostUncovered, leastUncovered}},
+		{"?sort=name", []string{"azurerm_linux_web_app", "azurerm_resource_group"}},
+		// q is case insensitive.
+		{"?q=WEB", []string{"azurerm_linux_web_app"}},
+		{"?q=not_found", []string{}},
+		{"?sort=name&limit=1", []string{"azurerm_linux_web_app"}},
+		{"?sort=name&limit=0", []string{"azurerm_linux_web_app", "azurerm_resource_group"}},
+		{"?sort=name&limit=10", []string{"azurerm_linux_web_app", "azurerm_resource_group"}},
+	}
+	for _, c := range cases {
+		var actual []resourceSummary
+		serve(t, s, http.MethodGet, "/api/resources"+c.query, http.StatusOK, &actual)
+		names := make([]string, 0, len(actual))
+		for _, res := range actual {
+			names = append(names, res.Name)
+		}
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("%s: resources = %v, expected %v", c.query, names, c.expected)
+		}
+	}
+
+	var actual []resourceSummary
+	serve(t, s, http.MethodGet, "/api/resources?q=web", http.StatusOK, &actual)
+	if expected := newResourceSummary(webApp); len(actual) != 1 || actual[0] != expected {
+		t.Errorf("resources = %+v, expected %+v", actual, expected)
+	}
+
+	for _, query := range []string{"?limit=-1", "?limit=one", "?sort=size"} {
+		var errResp map[string]string
+		serve(t, s, http.MethodGet, "/api/resources"+query, http.StatusBadRequest, &errResp)
+		if errResp["error"] == "" {
+			t.Errorf("%s: no error message", query)
+		}
+	}
+}
+
+func findNode(nodes []report.InspectNode, names ...string) (report.InspectNode, bool) {
+	for _, n := range nodes {
+		if n.Name != names[0] {
+			continue
+		}
+		if len(names) == 1 {
+			return n, true
+		}
+		return findNode(n.Children, names[1:]...)
+	}
+	return report.InspectNode{}, false
+}
+
+func TestResource(t *testing.T) {
+	snapshot := newTestSnapshot(t, nil)
+	s := New(snapshot)
+
+	var detail resourceDetail
+	serve(t, s, http.MethodGet, "/api/resources/azurerm_linux_web_app", http.StatusOK, &detail)
+	if expected := newResourceSummary(snapshot.Result.Resources["azurerm_linux_web_app"]); detail.resourceSummary != expected {
+		t.Errorf("summary = %+v, expected %+v", detail.resourceSummary, expected)
+	}
+	if expected := []string{"/site_config/0/not_a_property"}; !reflect.DeepEqual(detail.Orphans, expected) {
+		t.Errorf("orphans = %v, expected %v", detail.Orphans, expected)
+	}
+	cases := []struct {
+		names  []string
+		kind   string
+		status string
+	}{
+		{[]string{"name"}, report.InspectKindProperty, "covered"},
+		{[]string{"location"}, report.InspectKindProperty, "ignored"},
+		{[]string{"site_config"}, report.InspectKindBlock, ""},
+		{[]string{"site_config", "always_on"}, report.InspectKindProperty, "covered"},
+		{[]string{"site_config", "ip_restriction", "priority"}, report.InspectKindProperty, "covered"},
+	}
+	for _, c := range cases {
+		n, ok := findNode(detail.Properties, c.names...)
+		if !ok {
+			t.Errorf("%v not found", c.names)
+			continue
+		}
+		if n.Kind != c.kind || n.Status != c.status {
+			t.Errorf("%v = (%q, %q), expected (%q, %q)", c.names, n.Kind, n.Status, c.kind, c.status)
+		}
+	}
+
+	// a resource skipped as uncovered is in the schema but not in the result, all its properties are uncovered.
+	if _, ok := snapshot.Result.Resources["azurerm_windows_web_app"]; ok {
+		t.Fatal("azurerm_windows_web_app is not skipped")
+	}
+	detail = resourceDetail{}
+	serve(t, s, http.MethodGet, "/api/resources/azurerm_windows_web_app", http.StatusOK, &detail)
+	if detail.Name != "azurerm_windows_web_app" || detail.CoveredCnt != 0 || len(detail.Orphans) != 0 || len(detail.Properties) == 0 {
+		t.Errorf("detail of azurerm_windows_web_app = %+v", detail.resourceSummary)
+	}
+	if n, ok := findNode(detail.Properties, "name"); !ok || n.Status != "uncovered" {
+		t.Errorf("name of azurerm_windows_web_app = %+v", n)
+	}
+
+	for _, path := range []string{
+		"/api/resources/azurerm_not_in_schema",
+		"/api/resources/",
+		"/api/resources/azurerm_linux_web_app/unknown",
+		// an escaped `/` is part of the name, and the name is not unescaped twice.
+		"/api/resources/azurerm_linux_web_app%2Fexplain?pointer=/name",
+		"/api/resources/azurerm_linux_web_%2561pp",
+	} {
+		serve(t, s, http.MethodGet, path, http.StatusNotFound, nil)
+	}
+	// an escaped name is unescaped once.
+	serve(t, s, http.MethodGet, "/api/resources/azurerm_linux_web_%61pp", http.StatusOK, nil)
+}
+
+func TestExplain(t *testing.T) {
+	snapshot := newTestSnapshot(t, nil)
+	s := New(snapshot)
+
+	var actual runner.Explanation
+	serve(t, s, http.MethodGet, "/api/resources/azurerm_linux_web_app/explain?pointer=/site_config/0/ip_restriction/1/priority", http.StatusOK, &actual)
+	expected, err := snapshot.Runner.Explain("azurerm_linux_web_app", "/site_config/0/ip_restriction/1/priority")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&actual, expected) {
+		t.Errorf("explanation = %+v, expected %+v", actual, expected)
+	}
+	if actual.DisplayPointer != "/site_config/0/ip_restriction/0/priority" || !actual.Covered {
+		t.Errorf("display_pointer = %q, covered = %t", actual.DisplayPointer, actual.Covered)
+	}
+
+	for _, path := range []string{
+		"/api/resources/azurerm_linux_web_app/explain",
+		"/api/resources/azurerm_linux_web_app/explain?pointer=/not_a_property",
+	} {
+		var errResp map[string]string
+		serve(t, s, http.MethodGet, path, http.StatusBadRequest, &errResp)
+		if errResp["error"] == "" {
+			t.Errorf("%s: no error message", path)
+		}
+	}
+	serve(t, s, http.MethodGet, "/api/resources/azurerm_not_in_schema/explain?pointer=/name", http.StatusNotFound, nil)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	s := New(newTestSnapshot(t, nil))
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		serve(t, s, method, "/api/summary", http.StatusMethodNotAllowed, nil)
+	}
+	serve(t, s, http.MethodHead, "/api/summary", http.StatusOK, nil)
+}
+
+func TestIndex(t *testing.T) {
+	s := New(newTestSnapshot(t, nil))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/html; charset=utf-8" || rec.Body.Len() == 0 {
+		t.Errorf("index: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	serve(t, s, http.MethodGet, "/not_found", http.StatusNotFound, nil)
+}
+
+func TestUpdate(t *testing.T) {
+	s := New(newTestSnapshot(t, nil))
+
+	next := newTestSnapshot(t, func(coverageMap map[string]map[string][]jsonhelper.PropertyCoverage) {
+		delete(coverageMap, "azurerm_resource_group")
+	})
+	s.Update(next)
+
+	var actual summary
+	serve(t, s, http.MethodGet, "/api/summary", http.StatusOK, &actual)
+	if actual.ResourceCnt != 1 || actual.Totals != next.Result.Totals || !actual.LoadedAt.Equal(next.LoadedAt) {
+		t.Errorf("summary = %+v, expected the one of the updated snapshot", actual)
+	}
+	var resources []resourceSummary
+	serve(t, s, http.MethodGet, "/api/resources", http.StatusOK, &resources)
+	if len(resources) != 1 || resources[0].Name != "azurerm_linux_web_app" {
+		t.Errorf("resources = %+v", resources)
+	}
+}
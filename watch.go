@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/config"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/coverage"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/report"
+)
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+	missing bool
+}
+
+func statFile(path string) fileStat {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{missing: true}
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}
+}
+
+// fileWatcher polls the mtimes and sizes of files, so no platform specific notification is needed.
+type fileWatcher struct {
+	interval time.Duration
+	// map[path]fileStat of the last poll
+	stats map[string]fileStat
+}
+
+func newFileWatcher(interval time.Duration, paths ...string) *fileWatcher {
+	w := &fileWatcher{
+		interval: interval,
+		stats:    make(map[string]fileStat),
+	}
+	for _, path := range paths {
+		w.stats[path] = statFile(path)
+	}
+	return w
+}
+
+// wait blocks until any of the files is changed, and returns the changed paths sorted, or the error of ctx once ctx is done.
+func (w *fileWatcher) wait(ctx context.Context) ([]string, error) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		changed := make([]string, 0)
+		for path, last := range w.stats {
+			if stat := statFile(path); stat != last {
+				w.stats[path] = stat
+				changed = append(changed, path)
+			}
+		}
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			return changed, nil
+		}
+	}
+}
+
+// runWatch runs the report once, and then reruns the resources whose coverage entries are changed every time the coverage file is changed,
+// the schema is parsed only once. The delta of each rerun is printed, and the outputs written into files are rewritten.
+func runWatch(cfg config.Config, interval time.Duration) error {
+	rep, err := newReportWriter(cfg)
+	if err != nil {
+		return err
+	}
+
+	coverageMap, resources, err := loadInputs(cfg)
+	if err != nil {
+		return err
+	}
+	result, err := runReport(resources, coverageMap, cfg.IgnoreSchemas, cfg.IgnoreUncoveredResources)
+	if err != nil {
+		return err
+	}
+	if err := rep.write(result, true); err != nil {
+		return err
+	}
+	if err := rep.checkFailUnder(result); err != nil {
+		log.Println(err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	watcher := newFileWatcher(interval, cfg.Input)
+	log.Printf("watching %s for changes, press Ctrl-C to stop", cfg.Input)
+	for {
+		if _, err := watcher.wait(ctx); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+
+		// the errors of a rerun are only printed, the file may be in the middle of being written.
+		next, err := jsonhelper.ParseCoverageFile(cfg.Input)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		changed := changedResources(coverageMap, next)
+		if len(changed) == 0 {
+			continue
+		}
+
+		partial, err := runReport(subsetMap(resources, changed), subsetMap(next, changed), cfg.IgnoreSchemas, cfg.IgnoreUncoveredResources)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		updated := result.Update(changed, partial)
+
+		fmt.Printf("[%s] %d resources changed\n", time.Now().Format("15:04:05"), len(changed))
+		if err := report.RenderDiff(os.Stdout, coverage.Diff(result, updated), report.DiffOptions{
+			Compact: true,
+			Color:   cfg.Table.Color,
+		}); err != nil {
+			return err
+		}
+		coverageMap, result = next, updated
+
+		if err := rep.write(result, false); err != nil {
+			log.Println(err.Error())
+		}
+		if err := rep.checkFailUnder(result); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+// changedResources returns the resources whose coverage entries differ between the two coverage maps, sorted by name.
+func changedResources(base, head map[string]map[string][]jsonhelper.PropertyCoverage) []string {
+	result := make([]string, 0)
+	for name, entries := range head {
+		if !reflect.DeepEqual(base[name], entries) {
+			result = append(result, name)
+		}
+	}
+	for name := range base {
+		if _, ok := head[name]; !ok {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// subsetMap returns the values of m under names, the names not in m are skipped.
+func subsetMap[T any](m map[string]T, names []string) map[string]T {
+	result := make(map[string]T)
+	for _, name := range names {
+		if v, ok := m[name]; ok {
+			result[name] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ziyeqf/terraform-azurerm-provider-coverage/jsonhelper"
+)
+
+func TestFileWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := newFileWatcher(time.Millisecond, path)
+
+	if err := os.WriteFile(path, []byte(`{"azurerm_resource_group": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := w.wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{path}) {
+		t.Errorf("changed = %v, expected %v", changed, []string{path})
+	}
+
+	// the error of the context is returned once it's done.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := w.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait returned %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestChangedResources(t *testing.T) {
+	base := map[string]map[string][]jsonhelper.PropertyCoverage{
+		"azurerm_same":    {"/name": {{Addr: "name"}}},
+		"azurerm_changed": {"/name": {{Addr: "name"}}},
+		"azurerm_removed": {"/name": {{Addr: "name"}}},
+	}
+	head := map[string]map[string][]jsonhelper.PropertyCoverage{
+		"azurerm_same":    {"/name": {{Addr: "name"}}},
+		"azurerm_changed": {"/name": {{Addr: "properties.name"}}},
+		"azurerm_added":   {"/name": {{Addr: "name"}}},
+	}
+	expected := []string{"azurerm_added", "azurerm_changed", "azurerm_removed"}
+	if changed := changedResources(base, head); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("changedResources = %v, expected %v", changed, expected)
+	}
+	if changed := changedResources(base, base); len(changed) != 0 {
+		t.Errorf("changedResources of the same maps = %v", changed)
+	}
+}
+
+func TestSubsetMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	expected := map[string]int{"a": 1, "c": 3}
+	if actual := subsetMap(m, []string{"a", "c", "not_found"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("subsetMap = %v, expected %v", actual, expected)
+	}
+}
+
+// TestWatchRerun reruns only the changed resources the way runWatch does, the updated result must be the same as a full run.
+func TestWatchRerun(t *testing.T) {
+	schema, err := jsonhelper.ParseSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := schema.ProviderSchema.ResourcesMap
+	ignoreSchemas := []string{"location"}
+
+	base, err := jsonhelper.ParseCoverageFile("testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := jsonhelper.ParseCoverageFile("testdata/coverage_nested.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the orphan of azurerm_linux_web_app is fixed, azurerm_resource_group is removed and azurerm_windows_web_app is added,
+	// the orphans of azurerm_not_in_schema are unchanged.
+	delete(head["azurerm_linux_web_app"], "/site_config/0/not_a_property")
+	delete(head, "azurerm_resource_group")
+	head["azurerm_windows_web_app"] = map[string][]jsonhelper.PropertyCoverage{"/name": {{Addr: "name"}}}
+
+	changed := changedResources(base, head)
+	if expected := []string{"azurerm_linux_web_app", "azurerm_resource_group", "azurerm_windows_web_app"}; !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("changedResources = %v, expected %v", changed, expected)
+	}
+
+	result, err := runReport(resources, base, ignoreSchemas, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial, err := runReport(subsetMap(resources, changed), subsetMap(head, changed), ignoreSchemas, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := result.Update(changed, partial)
+
+	expected, err := runReport(resources, head, ignoreSchemas, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Totals != expected.Totals {
+		t.Errorf("totals = %+v, expected %+v", updated.Totals, expected.Totals)
+	}
+	if !reflect.DeepEqual(updated.Resources, expected.Resources) {
+		t.Errorf("resources differ from a full run")
+	}
+	if !reflect.DeepEqual(updated.Orphans, expected.Orphans) {
+		t.Errorf("orphans = %v, expected %v", updated.Orphans, expected.Orphans)
+	}
+	if _, ok := updated.Orphans["azurerm_linux_web_app"]; ok {
+		t.Errorf("the orphans of azurerm_linux_web_app are not replaced")
+	}
+	if _, ok := updated.Orphans["azurerm_not_in_schema"]; !ok {
+		t.Errorf("the orphans of azurerm_not_in_schema are removed")
+	}
+	if _, ok := updated.Resources["azurerm_resource_group"]; ok {
+		t.Errorf("azurerm_resource_group is not removed")
+	}
+	if _, ok := updated.Resources["azurerm_windows_web_app"]; !ok {
+		t.Errorf("azurerm_windows_web_app is not added")
+	}
+}